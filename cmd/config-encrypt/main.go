@@ -0,0 +1,50 @@
+// 配置加密工具, 生成配置文件中使用的 ENC(...) 值
+//
+//	LOOKLAPI_CONFIG_KEY=0123456789abcdef go run ./cmd/config-encrypt 'root:123456@tcp(127.0.0.1:3306)/testdb'
+//	go run ./cmd/config-encrypt -key 0123456789abcdef -decrypt 'ENC(...)'
+package main
+
+import (
+	"flag"
+	"fmt"
+	"looklapi/config/secret"
+	"os"
+)
+
+func main() {
+	key := flag.String("key", "", "encrypt key, default read from "+secret.KeyEnv+" or "+secret.KeyFileEnv)
+	decrypt := flag.Bool("decrypt", false, "decrypt an ENC(...) value")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: config-encrypt [-key KEY] [-decrypt] VALUE")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *key == "" {
+		loaded, err := secret.LoadKey()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		*key = loaded
+	}
+
+	var result string
+	var err error
+	if *decrypt {
+		result, err = secret.Decrypt(flag.Arg(0), *key)
+	} else {
+		result, err = secret.Encrypt(flag.Arg(0), *key)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Println(result)
+}
@@ -0,0 +1,522 @@
+// 拦截器代理生成工具, 为接口生成转发到 wireutils.Advisor 的代理并绑定到默认容器
+// 拦截器通过 wireutils.InterceptType[T] 注册
+//
+//	go run ./cmd/wiregen -dir services/srv-isrv -type TestSrvInterface -out services/srv-proxy/test_srv_aop.go
+//
+// 也可在接口文件中声明
+//
+//	//go:generate go run looklapi/cmd/wiregen -type TestSrvInterface
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package declaring the interface")
+	typ := flag.String("type", "", "interface name")
+	out := flag.String("out", "", "output file, default <dir>/<type>_aop.go")
+	pkg := flag.String("pkg", "", "package name of the output file, default detected from the output directory")
+	name := flag.String("name", "", "proxy struct name, default <type>Aop")
+	priority := flag.Int("priority", 0, "binding priority of the proxy")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: wiregen -type NAME [-dir DIR] [-out FILE] [-pkg NAME] [-name NAME] [-priority N]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typ == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *out == "" {
+		*out = filepath.Join(*dir, strings.ToLower(*typ)+"_aop.go")
+	}
+	if *name == "" {
+		*name = lowerFirst(*typ) + "Aop"
+	}
+
+	src, err := generate(*dir, *typ, *out, *pkg, *name, *priority)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+// 源码包
+type sourcePackage struct {
+	name       string
+	importPath string
+	types      map[string]*ast.TypeSpec // 包级类型声明
+	files      map[*ast.TypeSpec]*ast.File
+}
+
+// 接口方法
+type method struct {
+	name    string
+	params  []ast.Expr
+	results []ast.Expr
+	file    *ast.File
+}
+
+// 代码生成器
+type generator struct {
+	src     *sourcePackage
+	local   bool              // 输出文件与接口在同一个包
+	imports map[string]string // 使用的导入 path -> 别名
+}
+
+func generate(dir, typ, out, pkg, name string, priority int) ([]byte, error) {
+	src, err := parsePackage(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	srcDir, _ := filepath.Abs(dir)
+	out, _ = filepath.Abs(out)
+	outDir := filepath.Dir(out)
+	g := &generator{src: src, local: srcDir == outDir, imports: map[string]string{}}
+	if pkg == "" {
+		if g.local {
+			pkg = src.name
+		} else {
+			pkg = packageName(outDir, out)
+		}
+	}
+
+	methods, err := g.methods(typ, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	itype := typ
+	if !g.local {
+		itype = src.name + "." + typ
+		g.imports[src.importPath] = ""
+	}
+
+	body := &bytes.Buffer{}
+	fmt.Fprintf(body, "// %s 的拦截器代理, 方法调用经过 wireutils.InterceptType[%s] 注册的拦截器\n", itype, itype)
+	fmt.Fprintf(body, "type %s struct {\n", name)
+	fmt.Fprintf(body, "next %s `wired:\"Autowired\"`\n", itype)
+	fmt.Fprintf(body, "advisor *wireutils.Advisor `wired:\"Autowired\"`\n")
+	fmt.Fprintf(body, "}\n\n")
+	fmt.Fprintf(body, "func init() {\n")
+	fmt.Fprintf(body, "wireutils.BindAs[%s](&%s{}, true, %d)\n", itype, name, priority)
+	fmt.Fprintf(body, "}\n")
+
+	for _, m := range methods {
+		if err := g.writeMethod(body, name, m); err != nil {
+			return nil, err
+		}
+	}
+
+	g.imports["looklapi/common/wireutils"] = ""
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by wiregen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkg)
+	fmt.Fprintf(buf, "import (\n")
+	for _, path := range paths {
+		if alias := g.imports[path]; alias != "" {
+			fmt.Fprintf(buf, "%s %s\n", alias, strconv.Quote(path))
+		} else {
+			fmt.Fprintf(buf, "%s\n", strconv.Quote(path))
+		}
+	}
+	fmt.Fprintf(buf, ")\n\n")
+	buf.Write(body.Bytes())
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %s", err.Error())
+	}
+	return formatted, nil
+}
+
+// 解析目录中的包
+func parsePackage(dir string) (*sourcePackage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	src := &sourcePackage{types: map[string]*ast.TypeSpec{}, files: map[*ast.TypeSpec]*ast.File{}}
+	for _, entry := range entries {
+		fname := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(fname, ".go") || strings.HasSuffix(fname, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, fname), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		src.name = file.Name.Name
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				src.types[ts.Name.Name] = ts
+				src.files[ts] = file
+			}
+		}
+	}
+	if src.name == "" {
+		return nil, fmt.Errorf("no go files in %s", dir)
+	}
+
+	src.importPath, err = importPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// 按go.mod计算目录的导入路径
+func importPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for root := abs; ; root = filepath.Dir(root) {
+		data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				line = strings.TrimSpace(line)
+				if strings.HasPrefix(line, "module ") {
+					module := strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module")), "\"")
+					rel, _ := filepath.Rel(root, abs)
+					if rel == "." {
+						return module, nil
+					}
+					return module + "/" + filepath.ToSlash(rel), nil
+				}
+			}
+			return "", fmt.Errorf("no module declared in %s", filepath.Join(root, "go.mod"))
+		}
+		if filepath.Dir(root) == root {
+			return "", errors.New("go.mod is not found")
+		}
+	}
+}
+
+// 输出目录的包名, 目录中没有其他go文件时使用目录名
+func packageName(dir, out string) string {
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		fname := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || !strings.HasSuffix(fname, ".go") || strings.HasSuffix(fname, "_test.go") || fname == out {
+			continue
+		}
+		if file, err := parser.ParseFile(token.NewFileSet(), fname, nil, parser.PackageClauseOnly); err == nil {
+			return file.Name.Name
+		}
+	}
+	return strings.ReplaceAll(filepath.Base(dir), "-", "_")
+}
+
+// 接口的方法, 包含同一个包中嵌入的接口
+func (g *generator) methods(typ string, visited map[string]bool) ([]*method, error) {
+	if visited[typ] {
+		return nil, nil
+	}
+	visited[typ] = true
+
+	ts, ok := g.src.types[typ]
+	if !ok {
+		return nil, fmt.Errorf("type %s is not found in package %s", typ, g.src.name)
+	}
+	iface, ok := ts.Type.(*ast.InterfaceType)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not an interface", typ)
+	}
+	if ts.TypeParams != nil {
+		return nil, fmt.Errorf("generic interface %s is not supported", typ)
+	}
+
+	var methods []*method
+	for _, field := range iface.Methods.List {
+		switch ft := field.Type.(type) {
+		case *ast.FuncType:
+			for _, name := range field.Names {
+				methods = append(methods, &method{
+					name:    name.Name,
+					params:  fieldTypes(ft.Params),
+					results: fieldTypes(ft.Results),
+					file:    g.src.files[ts],
+				})
+			}
+		case *ast.Ident:
+			embedded, err := g.methods(ft.Name, visited)
+			if err != nil {
+				return nil, err
+			}
+			methods = append(methods, embedded...)
+		default:
+			return nil, fmt.Errorf("embedded %s in %s is not supported", g.expr(field.Type), typ)
+		}
+	}
+
+	// 去除嵌入接口中的重复方法
+	seen := map[string]bool{}
+	unique := methods[:0]
+	for _, m := range methods {
+		if !seen[m.name] {
+			seen[m.name] = true
+			unique = append(unique, m)
+		}
+	}
+	return unique, nil
+}
+
+// 参数或返回值的类型列表, 多个名称共用类型时展开
+func fieldTypes(list *ast.FieldList) []ast.Expr {
+	if list == nil {
+		return nil
+	}
+
+	var types []ast.Expr
+	for _, field := range list.List {
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			types = append(types, field.Type)
+		}
+	}
+	return types
+}
+
+// 生成代理方法
+func (g *generator) writeMethod(buf *bytes.Buffer, name string, m *method) error {
+	var params, args, callArgs, unpack []string
+	for i, param := range m.params {
+		arg := fmt.Sprintf("a%d", i)
+		args = append(args, arg)
+
+		if ellipsis, ok := param.(*ast.Ellipsis); ok {
+			elt, err := g.typeString(ellipsis.Elt, m.file)
+			if err != nil {
+				return err
+			}
+			params = append(params, arg+" ..."+elt)
+			callArgs = append(callArgs, arg+"...")
+			unpack = append(unpack, fmt.Sprintf("%s, _ := inv.Arg(%d).([]%s)", arg, i, elt))
+			continue
+		}
+
+		typ, err := g.typeString(param, m.file)
+		if err != nil {
+			return err
+		}
+		params = append(params, arg+" "+typ)
+		callArgs = append(callArgs, arg)
+		unpack = append(unpack, fmt.Sprintf("%s, _ := inv.Arg(%d).(%s)", arg, i, typ))
+	}
+
+	var results, types []string
+	hasErr := false
+	for i, result := range m.results {
+		typ, err := g.typeString(result, m.file)
+		if err != nil {
+			return err
+		}
+		if i == len(m.results)-1 && typ == "error" {
+			hasErr = true
+		} else {
+			results = append(results, fmt.Sprintf("r%d", i))
+		}
+		types = append(types, typ)
+	}
+
+	signature := strings.Join(types, ", ")
+	if len(types) > 1 {
+		signature = "(" + signature + ")"
+	}
+
+	fmt.Fprintf(buf, "\nfunc (proxy *%s) %s(%s) %s {\n", name, m.name, strings.Join(params, ", "), signature)
+	// 没有返回值时不使用调用结果
+	assign := "inv := "
+	if len(results) == 0 && !hasErr {
+		assign = ""
+	}
+	fmt.Fprintf(buf, "%sproxy.advisor.Invoke(proxy.next, %s, []interface{}{%s}, func(inv *wireutils.Invocation) {\n",
+		assign, strconv.Quote(m.name), strings.Join(args, ", "))
+	for _, line := range unpack {
+		fmt.Fprintln(buf, line)
+	}
+
+	call := fmt.Sprintf("proxy.next.%s(%s)", m.name, strings.Join(callArgs, ", "))
+	switch {
+	case len(results) == 0 && !hasErr:
+		fmt.Fprintln(buf, call)
+	case len(results) == 0:
+		fmt.Fprintf(buf, "inv.Err = %s\n", call)
+	default:
+		vars := append([]string{}, results...)
+		if hasErr {
+			vars = append(vars, "err")
+		}
+		fmt.Fprintf(buf, "%s := %s\n", strings.Join(vars, ", "), call)
+		fmt.Fprintf(buf, "inv.Results = []interface{}{%s}\n", strings.Join(results, ", "))
+		if hasErr {
+			fmt.Fprintln(buf, "inv.Err = err")
+		}
+	}
+	fmt.Fprintln(buf, "})")
+
+	var returns []string
+	for i, result := range results {
+		fmt.Fprintf(buf, "%s, _ := inv.Result(%d).(%s)\n", result, i, types[i])
+		returns = append(returns, result)
+	}
+	if hasErr {
+		returns = append(returns, "inv.Err")
+	}
+	if len(returns) > 0 {
+		fmt.Fprintf(buf, "return %s\n", strings.Join(returns, ", "))
+	}
+	fmt.Fprintln(buf, "}")
+	return nil
+}
+
+// 类型表达式, 非同一个包时为源码包中的类型加上包名, 并记录使用的导入
+func (g *generator) typeString(expr ast.Expr, file *ast.File) (string, error) {
+	var err error
+	qualified := g.qualify(expr, file, &err)
+	if err != nil {
+		return "", err
+	}
+	return g.expr(qualified), nil
+}
+
+func (g *generator) qualify(expr ast.Expr, file *ast.File, err *error) ast.Expr {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if _, ok := g.src.types[t.Name]; ok && !g.local {
+			if !isExported(t.Name) {
+				*err = fmt.Errorf("unexported type %s can not be used outside package %s", t.Name, g.src.name)
+				return t
+			}
+			g.imports[g.src.importPath] = ""
+			return &ast.SelectorExpr{X: ast.NewIdent(g.src.name), Sel: t}
+		}
+		return t
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			path, alias, found := lookupImport(file, x.Name)
+			if !found {
+				*err = fmt.Errorf("import of %s is not found", x.Name)
+				return t
+			}
+			g.imports[path] = alias
+		}
+		return t
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: g.qualify(t.X, file, err)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: t.Len, Elt: g.qualify(t.Elt, file, err)}
+	case *ast.MapType:
+		return &ast.MapType{Key: g.qualify(t.Key, file, err), Value: g.qualify(t.Value, file, err)}
+	case *ast.ChanType:
+		return &ast.ChanType{Dir: t.Dir, Value: g.qualify(t.Value, file, err)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: g.qualify(t.Elt, file, err)}
+	case *ast.ParenExpr:
+		return &ast.ParenExpr{X: g.qualify(t.X, file, err)}
+	case *ast.IndexExpr:
+		return &ast.IndexExpr{X: g.qualify(t.X, file, err), Index: g.qualify(t.Index, file, err)}
+	case *ast.IndexListExpr:
+		indices := make([]ast.Expr, len(t.Indices))
+		for i, index := range t.Indices {
+			indices[i] = g.qualify(index, file, err)
+		}
+		return &ast.IndexListExpr{X: g.qualify(t.X, file, err), Indices: indices}
+	case *ast.FuncType:
+		return &ast.FuncType{Params: g.qualifyFields(t.Params, file, err), Results: g.qualifyFields(t.Results, file, err)}
+	case *ast.StructType:
+		return &ast.StructType{Fields: g.qualifyFields(t.Fields, file, err)}
+	case *ast.InterfaceType:
+		return &ast.InterfaceType{Methods: g.qualifyFields(t.Methods, file, err)}
+	default:
+		return t
+	}
+}
+
+func (g *generator) qualifyFields(list *ast.FieldList, file *ast.File, err *error) *ast.FieldList {
+	if list == nil {
+		return nil
+	}
+
+	fields := &ast.FieldList{}
+	for _, field := range list.List {
+		fields.List = append(fields.List, &ast.Field{Names: field.Names, Type: g.qualify(field.Type, file, err), Tag: field.Tag})
+	}
+	return fields
+}
+
+// 按包名查找文件中的导入, 未指定别名时按路径最后一段匹配
+func lookupImport(file *ast.File, name string) (path, alias string, found bool) {
+	for _, spec := range file.Imports {
+		path, _ = strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil {
+			if spec.Name.Name == name {
+				return path, name, true
+			}
+			continue
+		}
+
+		base := path[strings.LastIndex(path, "/")+1:]
+		if base == name || strings.ReplaceAll(base, "-", "_") == name {
+			return path, "", true
+		}
+	}
+	return "", "", false
+}
+
+func (g *generator) expr(expr ast.Expr) string {
+	buf := &bytes.Buffer{}
+	printer.Fprint(buf, token.NewFileSet(), expr)
+	return buf.String()
+}
+
+func isExported(name string) bool {
+	return name != "" && unicode.IsUpper([]rune(name)[0])
+}
+
+func lowerFirst(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
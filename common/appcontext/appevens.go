@@ -1,10 +1,48 @@
 package appcontext
 
+import (
+	"strings"
+	"time"
+)
+
 // 应用启动完成
 type AppEventInitCompleted int
 
 // 依赖注入完成
 type AppEventBeanInjected int
 
-// 应用配置加载完成
+// 应用配置加载完成, 由config.Watch在启动时同步发布
 type AppEventConfigInitialized int
+
+// 应用配置变更
+type AppEventConfigChanged struct {
+	Keys []string // 变更的配置项, 如 redis.host
+}
+
+// 是否变更了指定前缀的配置
+// 如 Changed("redis") 匹配 redis.host, redis.port
+func (event AppEventConfigChanged) Changed(prefix string) bool {
+	for _, key := range event.Keys {
+		if key == prefix || strings.HasPrefix(key, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// web服务启动完成, 已开始监听端口
+type AppEventServerStarted struct {
+	Addr string // 监听地址
+}
+
+// 应用请求停机
+// 订阅者须停止接收新任务, 并在Deadline前完成处理中的任务
+type AppEventShutdownRequested struct {
+	Deadline time.Time
+}
+
+// 应用停机完成, web服务已关闭且实例已销毁
+// 订阅者须在Deadline前释放剩余资源, 如写入缓冲的日志
+type AppEventShutdownCompleted struct {
+	Deadline time.Time
+}
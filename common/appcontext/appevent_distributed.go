@@ -0,0 +1,104 @@
+package appcontext
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// 分布式事件注册表
+var distributed = &distributedRegistry{
+	mu:    &sync.RWMutex{},
+	types: make(map[string]reflect.Type),
+	names: make(map[reflect.Type]string),
+}
+
+type distributedRegistry struct {
+	mu        *sync.RWMutex
+	types     map[string]reflect.Type // 事件名称 -> 事件类型
+	names     map[reflect.Type]string // 事件类型 -> 事件名称
+	transport func(name string, event interface{}) error
+}
+
+// 将T类型注册为分布式事件, name为集群中事件的唯一名称, 用于接收时解码
+// 发布分布式事件时, 本实例的观察者照常处理, 同时通过传输层广播到其他实例, 由其他实例的观察者处理
+// 未设置传输层(如未启用mq)时仅在本实例发布
+//
+//	appcontext.RegisterDistributedEvent[*CacheEvicted]("cache.evicted")
+func RegisterDistributedEvent[T any](name string) {
+	if name == "" {
+		panic("distributed event name is empty")
+	}
+	etype := reflect.TypeOf((*T)(nil)).Elem()
+	if etype.Kind() == reflect.Interface {
+		panic(fmt.Sprintf("distributed event %s must be a concrete type", etype.String()))
+	}
+
+	distributed.mu.Lock()
+	defer distributed.mu.Unlock()
+
+	if exist, ok := distributed.types[name]; ok && exist != etype {
+		panic(fmt.Sprintf("distributed event %s is already registered by %s", name, exist.String()))
+	}
+	if exist, ok := distributed.names[etype]; ok && exist != name {
+		panic(fmt.Sprintf("distributed event %s is already registered as %s", etype.String(), exist))
+	}
+	distributed.types[name] = etype
+	distributed.names[etype] = name
+}
+
+// 分布式事件的类型, 用于接收时解码
+func DistributedEventType(name string) (reflect.Type, bool) {
+	distributed.mu.RLock()
+	defer distributed.mu.RUnlock()
+
+	etype, ok := distributed.types[name]
+	return etype, ok
+}
+
+// 设置分布式事件的传输层, 由mqutils在启用mq时设置
+// send 将事件广播到集群中的所有实例, 接收方通过DeliverEvent在本实例发布
+func SetEventTransport(send func(name string, event interface{}) error) {
+	distributed.mu.Lock()
+	defer distributed.mu.Unlock()
+
+	distributed.transport = send
+}
+
+// 是否为已注册的分布式事件
+func isDistributed(event interface{}) bool {
+	if event == nil {
+		return false
+	}
+
+	distributed.mu.RLock()
+	defer distributed.mu.RUnlock()
+
+	_, ok := distributed.names[reflect.TypeOf(event)]
+	return ok
+}
+
+// 广播分布式事件, 不是分布式事件或未设置传输层时忽略
+func broadcast(event interface{}) error {
+	if event == nil {
+		return nil
+	}
+
+	distributed.mu.RLock()
+	name, ok := distributed.names[reflect.TypeOf(event)]
+	send := distributed.transport
+	distributed.mu.RUnlock()
+
+	if !ok || send == nil {
+		return nil
+	}
+	if err := send(name, event); err != nil {
+		return fmt.Errorf("broadcast distributed event %s: %s", name, err.Error())
+	}
+	return nil
+}
+
+// 在本实例同步发布从其他实例接收的分布式事件, 不再广播
+func (publisher *appEventPublisher) DeliverEvent(event interface{}) error {
+	return publisher.publishLocalSync(event)
+}
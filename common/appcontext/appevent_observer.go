@@ -3,6 +3,7 @@ package appcontext
 import (
 	"fmt"
 	"log"
+	"sync/atomic"
 )
 
 // an AppObserver is one of subscriber that subscribe the application runtime event
@@ -16,16 +17,78 @@ type AppObserver interface {
 	Subscribe()
 }
 
-func onEvent(observer AppObserver, event interface{}) {
+// an AppEventHandler is an observer which reports the handling error.
+// when the event is published synchronously, HandleApplicationEvent is called instead of OnApplicationEvent
+// and the error is returned to the publisher
+type AppEventHandler interface {
+	AppObserver
+
+	HandleApplicationEvent(event interface{}) error
+}
+
+// 异步处理事件panic或返回错误时的处理函数
+var panicHandler atomic.Value
+
+// 设置异步处理事件panic或返回错误时的处理函数, 默认输出到标准日志
+// appcontext不依赖loggers, 由loggers在初始化时设置
+func SetPanicHandler(handler func(r interface{})) {
+	if handler != nil {
+		panicHandler.Store(handler)
+	}
+}
+
+func onEvent(sub *subscriber, event interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			reportPanic(r)
+		}
+	}()
+
+	if sub.handle != nil {
+		if err := sub.handle(event); err != nil {
+			reportPanic(err)
+		}
+		return
+	}
+	sub.observer.OnApplicationEvent(event)
+}
+
+// 输出异步处理事件时的panic或错误
+func reportPanic(r interface{}) {
+	if handler, ok := panicHandler.Load().(func(r interface{})); ok {
+		handler(r)
+		return
+	}
+	log.Println(fmt.Sprintf("%v %v", "ERROR", r))
+}
+
+// 同步处理事件, panic时转为错误
+func handleEvent(sub *subscriber, event interface{}) (err error) {
 	defer func() {
-		if err := recover(); err != nil {
-			if throw, ok := err.(error); ok {
-				log.Println(fmt.Sprintf("%v %s", "ERROR", throw.Error()))
-			} else if msg, ok := err.(string); ok {
-				log.Println(fmt.Sprintf("%v %s", "ERROR", msg))
-			}
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s handle %T panic: %v", sub.name(), event, r)
 		}
 	}()
 
-	observer.OnApplicationEvent(event)
+	var handleErr error
+	if sub.handle != nil {
+		handleErr = sub.handle(event)
+	} else if handler, ok := sub.observer.(AppEventHandler); ok {
+		handleErr = handler.HandleApplicationEvent(event)
+	} else {
+		sub.observer.OnApplicationEvent(event)
+	}
+
+	if handleErr != nil {
+		return fmt.Errorf("%s handle %T: %s", sub.name(), event, handleErr.Error())
+	}
+	return nil
+}
+
+// 订阅者名称, 用于错误信息
+func (sub *subscriber) name() string {
+	if sub.observer != nil {
+		return fmt.Sprintf("%T", sub.observer)
+	}
+	return fmt.Sprintf("On[%s]", sub.eventType.String())
 }
@@ -1,15 +1,26 @@
 package appcontext
 
 import (
+	"errors"
 	"reflect"
+	"sort"
 	"sync"
 )
 
 // the application event publisher.
 // it is one instance on the application runtime
 type appEventPublisher struct {
-	observers map[reflect.Type][]AppObserver
-	mu        *sync.Mutex
+	subscribers []*subscriber // 按priority由小到大排列
+	mu          *sync.Mutex
+}
+
+// 订阅者, 同步发布时按priority由小到大执行
+type subscriber struct {
+	eventType reflect.Type
+	observer  AppObserver                   // 通过Subscribe订阅时的观察者
+	handle    func(event interface{}) error // 通过On订阅时的处理函数
+	priority  int
+	publisher *appEventPublisher
 }
 
 var eventPublisher *appEventPublisher
@@ -19,7 +30,7 @@ var once = &sync.Once{}
 func GetAppEventPublisher() *appEventPublisher {
 	if eventPublisher == nil {
 		once.Do(func() {
-			eventPublisher = &appEventPublisher{mu: &sync.Mutex{}, observers: make(map[reflect.Type][]AppObserver)}
+			eventPublisher = &appEventPublisher{mu: &sync.Mutex{}}
 		})
 	}
 
@@ -29,38 +40,115 @@ func GetAppEventPublisher() *appEventPublisher {
 // register observer to the application event publisher
 // @eventType the event type which the observer interested in
 func (publisher *appEventPublisher) Subscribe(observer AppObserver, eventType reflect.Type) {
+	publisher.SubscribeWithPriority(observer, eventType, 0)
+}
+
+// register observer with priority, observers with smaller priority handle the event first when published synchronously
+// @eventType the event type which the observer interested in. an interface type receives all the events implementing it
+func (publisher *appEventPublisher) SubscribeWithPriority(observer AppObserver, eventType reflect.Type, priority int) {
 	if observer == nil || eventType == nil {
 		return
 	}
 	publisher.mu.Lock()
 	defer publisher.mu.Unlock()
 
-	needAdd := true
-	if obs, ok := publisher.observers[eventType]; ok {
-		for _, ob := range obs {
-			if ob == observer {
-				needAdd = false
-				break
-			}
+	for _, sub := range publisher.subscribers {
+		if sub.observer == observer && sub.eventType == eventType {
+			return
+		}
+	}
+	publisher.add(&subscriber{eventType: eventType, observer: observer, priority: priority})
+}
+
+// 添加订阅者并按priority排序, 调用方须持有锁
+func (publisher *appEventPublisher) add(sub *subscriber) {
+	sub.publisher = publisher
+	publisher.subscribers = append(publisher.subscribers, sub)
+	sort.SliceStable(publisher.subscribers, func(i, j int) bool {
+		return publisher.subscribers[i].priority < publisher.subscribers[j].priority
+	})
+}
+
+// 取消订阅
+func (sub *subscriber) Cancel() {
+	publisher := sub.publisher
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	for i, item := range publisher.subscribers {
+		if item == sub {
+			publisher.subscribers = append(publisher.subscribers[:i:i], publisher.subscribers[i+1:]...)
+			return
 		}
 	}
-	if needAdd {
-		publisher.observers[eventType] = append(publisher.observers[eventType], observer)
+}
+
+// 是否订阅了事件, 订阅接口类型时匹配实现该接口的事件
+func (sub *subscriber) matches(etype reflect.Type) bool {
+	if sub.eventType == etype {
+		return true
 	}
+	return sub.eventType.Kind() == reflect.Interface && etype.Implements(sub.eventType)
 }
 
 // publish any event to the observers that
-// have registered to the application event publisher
+// have registered to the application event publisher.
+// the same as PublishEventAsync
 func (publisher *appEventPublisher) PublishEvent(event interface{}) {
+	publisher.PublishEventAsync(event)
+}
+
+// publish event asynchronously, each observer handles the event in its own goroutine
+// and the order is undefined.
+// the distributed event is broadcast to the other instances as well
+func (publisher *appEventPublisher) PublishEventAsync(event interface{}) {
+	for _, sub := range publisher.matched(event) {
+		go onEvent(sub, event)
+	}
+
+	if isDistributed(event) {
+		go func() {
+			if err := broadcast(event); err != nil {
+				reportPanic(err)
+			}
+		}()
+	}
+}
+
+// publish event synchronously, observers handle the event one by one ordered by priority.
+// returns when all observers finished, with the errors returned by AppEventHandler and the recovered panics.
+// the distributed event is broadcast to the other instances after the local observers finished
+func (publisher *appEventPublisher) PublishEventSync(event interface{}) error {
+	err := publisher.publishLocalSync(event)
+	return errors.Join(err, broadcast(event))
+}
+
+// 在本实例同步发布事件
+func (publisher *appEventPublisher) publishLocalSync(event interface{}) error {
+	var errs []error
+	for _, sub := range publisher.matched(event) {
+		if err := handleEvent(sub, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 订阅了事件的订阅者快照, 发布期间新增或取消的订阅不影响本次发布
+func (publisher *appEventPublisher) matched(event interface{}) []*subscriber {
 	if event == nil {
-		return
+		return nil
 	}
 	etype := reflect.TypeOf(event)
-	if observers, ok := publisher.observers[etype]; ok {
-		for _, observer := range observers {
-			ob := observer
-			go onEvent(ob, event)
-			//observer.OnApplicationEvent(event)
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	var matched []*subscriber
+	for _, sub := range publisher.subscribers {
+		if sub.matches(etype) {
+			matched = append(matched, sub)
 		}
 	}
+	return matched
 }
@@ -0,0 +1,51 @@
+package appcontext
+
+import (
+	"reflect"
+)
+
+// 事件订阅, 通过Cancel取消
+type Subscription interface {
+	Cancel()
+}
+
+// 订阅T类型的事件, T为接口时接收所有实现该接口的事件
+//
+//	sub := appcontext.On(func(event appcontext.AppEventConfigChanged) {
+//		if event.Changed("redis") {
+//			reconnect()
+//		}
+//	})
+//	defer sub.Cancel()
+func On[T any](handler func(event T)) Subscription {
+	return OnWithPriority(handler, 0)
+}
+
+// 按优先级订阅T类型的事件, priority越小同步发布时越先处理
+func OnWithPriority[T any](handler func(event T), priority int) Subscription {
+	return OnWithErr(func(event T) error {
+		handler(event)
+		return nil
+	}, priority)
+}
+
+// 按优先级订阅T类型的事件, 同步发布时返回handler的错误
+func OnWithErr[T any](handler func(event T) error, priority int) Subscription {
+	if handler == nil {
+		panic("event handler is nil")
+	}
+
+	publisher := GetAppEventPublisher()
+	sub := &subscriber{
+		eventType: reflect.TypeOf((*T)(nil)).Elem(),
+		handle: func(event interface{}) error {
+			return handler(event.(T))
+		},
+		priority: priority,
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	publisher.add(sub)
+	return sub
+}
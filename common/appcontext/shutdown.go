@@ -0,0 +1,131 @@
+package appcontext
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var shutdownOnce = &sync.Once{}
+var shutdownErr error
+
+// 优雅停机, 只执行一次, 重复调用时返回第一次停机的结果
+// 1. 同步发布AppEventShutdownRequested, 订阅者(服务注册、mq消费者、定时任务等)停止接收新任务并完成处理中的任务
+// 2. 调用stop, 如关闭web服务及销毁实例
+// 3. 同步发布AppEventShutdownCompleted, 订阅者(如日志)释放剩余资源
+// 所有步骤须在timeout内完成, 超时后不再等待未完成的步骤, 并返回错误
+func Shutdown(timeout time.Duration, stop func(ctx context.Context) error) error {
+	shutdownOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		deadline, _ := ctx.Deadline()
+
+		var errs []error
+		if err := runUntil(ctx, "shutdown requested subscribers", func() error {
+			return GetAppEventPublisher().PublishEventSync(AppEventShutdownRequested{Deadline: deadline})
+		}); err != nil {
+			errs = append(errs, err)
+		}
+
+		if stop != nil {
+			if err := runUntil(ctx, "shutdown", func() error {
+				return stop(ctx)
+			}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if err := runUntil(ctx, "shutdown completed subscribers", func() error {
+			return GetAppEventPublisher().PublishEventSync(AppEventShutdownCompleted{Deadline: deadline})
+		}); err != nil {
+			errs = append(errs, err)
+		}
+
+		shutdownErr = errors.Join(errs...)
+	})
+
+	return shutdownErr
+}
+
+// 执行fn, 超过ctx的截止时间时不再等待
+func runUntil(ctx context.Context, name string, fn func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("%s panic: %v", name, r)
+			}
+		}()
+		done <- fn()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%s not finished before deadline", name)
+	}
+}
+
+// 处理中的任务, 停机时拒绝新任务并等待处理中的任务完成
+// 零值可用
+type Inflight struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	stopped bool
+}
+
+// 开始一个任务, 已停止时返回false, 返回true时须调用Done
+func (inflight *Inflight) Begin() bool {
+	inflight.mu.Lock()
+	defer inflight.mu.Unlock()
+
+	if inflight.stopped {
+		return false
+	}
+	inflight.wg.Add(1)
+	return true
+}
+
+// 任务完成
+func (inflight *Inflight) Done() {
+	inflight.wg.Done()
+}
+
+// 停止接收新任务
+func (inflight *Inflight) Stop() {
+	inflight.mu.Lock()
+	defer inflight.mu.Unlock()
+
+	inflight.stopped = true
+}
+
+// 是否已停止
+func (inflight *Inflight) Stopped() bool {
+	inflight.mu.Lock()
+	defer inflight.mu.Unlock()
+
+	return inflight.stopped
+}
+
+// 停止接收新任务, 并等待处理中的任务在deadline前完成
+func (inflight *Inflight) Wait(deadline time.Time) error {
+	inflight.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		inflight.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+		return errors.New("inflight tasks not finished before deadline")
+	}
+}
@@ -17,7 +17,7 @@ type consoleLogger struct {
 }
 
 func init() {
-	var logger = &consoleLogger{logLevel: level(appConfig.AppConfig.Logger.InitLevel)}
+	var logger = &consoleLogger{logLevel: level(appConfig.AppConfig().Logger.InitLevel)}
 	logger.setLogger()
 	logger.Subscribe()
 }
@@ -19,16 +19,18 @@ import (
 type fileLogger struct {
 	logLevel   logLevel // 日志等级
 	zeroLogger *zerolog.Logger
+	writer     *lumberjack.Logger // 日志文件
 }
 
 func init() {
-	var logger = &fileLogger{logLevel: level(appConfig.AppConfig.Logger.InitLevel)}
-	if appConfig.AppConfig.Logger.DefaultLogger != logger.name() {
+	var logger = &fileLogger{logLevel: level(appConfig.AppConfig().Logger.InitLevel)}
+	if appConfig.AppConfig().Logger.DefaultLogger != logger.name() {
 		return
 	}
 
 	logger.setLogger()
 	logger.Subscribe()
+	appcontext.OnWithErr(logger.shutdown, 0)
 }
 
 func (logger *fileLogger) name() string {
@@ -36,10 +38,11 @@ func (logger *fileLogger) name() string {
 }
 
 func (logger *fileLogger) setLogger() {
-	if myLogger, err := initLogger(); err != nil {
+	if myLogger, writer, err := initLogger(); err != nil {
 		panic(err)
 	} else {
 		logger.zeroLogger = myLogger
+		logger.writer = writer
 	}
 
 	setLogger(logger)
@@ -107,7 +110,15 @@ func (logger *fileLogger) Error(err error) {
 	logger.zeroLogger.Error().Stack().Err(err).Msg("")
 }
 
-func initLogger() (*zerolog.Logger, error) {
+// 停机完成时关闭日志文件
+func (logger *fileLogger) shutdown(event appcontext.AppEventShutdownCompleted) error {
+	if logger.writer == nil {
+		return nil
+	}
+	return logger.writer.Close()
+}
+
+func initLogger() (*zerolog.Logger, *lumberjack.Logger, error) {
 	zerolog.TimeFieldFormat = time.DateTime
 	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
 	zerolog.CallerMarshalFunc = func(file string, line int) string {
@@ -120,7 +131,7 @@ func initLogger() (*zerolog.Logger, error) {
 	dir := filepath.Dir(fileName)
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
@@ -137,5 +148,5 @@ func initLogger() (*zerolog.Logger, error) {
 	// 创建一个同时输出到控制台和文件的MultiWriter
 	mw := io.MultiWriter(zConsole, zFile)
 	myLogger := parent.Output(mw)
-	return &myLogger, nil
+	return &myLogger, fileLogger, nil
 }
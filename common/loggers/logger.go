@@ -39,21 +39,34 @@ type logManager struct {
 // received app event and process.
 // for event publish well, the developers must deal with the panic by their self
 func (manager *logManager) OnApplicationEvent(event interface{}) {
-	// todo may be reset log level from load remote config when AppEventBeanInjected
-	//logConfig := &ConfigLog{}
-	//appcontext.GetAppEventPublisher().PublishEvent(logConfig)
+	switch event := event.(type) {
+	case appcontext.AppEventConfigChanged:
+		// 配置变更时重置日志等级, 各实例分别重新加载配置, 只在本实例发布
+		if event.Changed("logger.init-level") {
+			logConfig := &ConfigLog{LogLevel: int8(level(appConfig.AppConfig().Logger.InitLevel))}
+			if err := appcontext.GetAppEventPublisher().DeliverEvent(logConfig); err != nil {
+				GetLogger().Error(err)
+			}
+		}
+	}
 }
 
 // register to the application event publisher
 func (manager *logManager) Subscribe() {
-	appcontext.GetAppEventPublisher().Subscribe(manager, reflect.TypeOf(appcontext.AppEventBeanInjected(0)))
+	appcontext.GetAppEventPublisher().Subscribe(manager, reflect.TypeOf(appcontext.AppEventConfigChanged{}))
 }
 
 func init() {
+	appcontext.RegisterDistributedEvent[*ConfigLog]("logger.level")
+
 	manager := &logManager{}
 	manager.Subscribe()
 }
 
+// 日志等级变更事件
+// 注册为分布式事件, 发布后集群中的所有实例同时变更日志等级
+//
+//	appcontext.GetAppEventPublisher().PublishEvent(&loggers.ConfigLog{LogLevel: 5})
 type ConfigLog struct {
 	LogLevel int8
 }
@@ -87,7 +100,7 @@ func setLogger(logger Logger) {
 
 	_loggers = append(_loggers, logger)
 
-	if logger.name() == appConfig.AppConfig.Logger.DefaultLogger {
+	if logger.name() == appConfig.AppConfig().Logger.DefaultLogger {
 		_defaultLogger = logger
 	}
 
@@ -15,12 +15,13 @@ import (
 
 // mongo日志
 type mongoLogger struct {
-	logLevel logLevel // 日志等级
+	logLevel logLevel            // 日志等级
+	inflight appcontext.Inflight // 异步写入中的日志
 }
 
 func init() {
-	var logger = &mongoLogger{logLevel: level(config.AppConfig.Logger.InitLevel)}
-	if config.AppConfig.Logger.DefaultLogger != logger.name() {
+	var logger = &mongoLogger{logLevel: level(config.AppConfig().Logger.InitLevel)}
+	if config.AppConfig().Logger.DefaultLogger != logger.name() {
 		return
 	}
 	if !mongoutils.ClientIsValid() {
@@ -29,6 +30,7 @@ func init() {
 
 	logger.setLogger()
 	logger.Subscribe()
+	appcontext.OnWithErr(logger.shutdown, 0)
 }
 
 func (logger *mongoLogger) name() string {
@@ -65,7 +67,7 @@ func (logger *mongoLogger) Debug(msg string) {
 
 	methodName, fullFileName, fileName, lineNum := getTrace()
 	log := mongo.NewMongoLog()
-	log.Instance = config.AppConfig.Server.Name
+	log.Instance = config.AppConfig().Server.Name
 	log.HostIp = utils.HostIp()
 	log.Content = msg
 	log.Level = int32(_DEBUG)
@@ -73,7 +75,7 @@ func (logger *mongoLogger) Debug(msg string) {
 	log.ClassName = fileName
 	log.Stacktrace = fmt.Sprintf("%s\n\t%s:%d", methodName, fullFileName, lineNum)
 
-	go func() {
+	logger.async(func() {
 		defer func() {
 			if err := recover(); err != nil {
 				fmt.Println(log.Content)
@@ -91,7 +93,7 @@ func (logger *mongoLogger) Debug(msg string) {
 			fmt.Println(log.Stacktrace)
 			fmt.Println(err.Error())
 		}
-	}()
+	})
 }
 
 // 提示
@@ -105,7 +107,7 @@ func (logger *mongoLogger) Info(msg string) {
 	}
 
 	log := mongo.NewMongoLog()
-	log.Instance = config.AppConfig.Server.Name
+	log.Instance = config.AppConfig().Server.Name
 	log.HostIp = utils.HostIp()
 	log.Content = msg
 	log.Level = int32(_INFO)
@@ -114,7 +116,7 @@ func (logger *mongoLogger) Info(msg string) {
 	log.ClassName = fileName
 	log.Stacktrace = fmt.Sprintf("%s\n\t%s:%d", methodName, fullFileName, lineNum)
 
-	go func() {
+	logger.async(func() {
 		defer func() {
 			if err := recover(); err != nil {
 				fmt.Println(log.Content)
@@ -132,7 +134,7 @@ func (logger *mongoLogger) Info(msg string) {
 			fmt.Println(log.Stacktrace)
 			fmt.Println(err.Error())
 		}
-	}()
+	})
 }
 
 // 警告
@@ -146,7 +148,7 @@ func (logger *mongoLogger) Warn(msg string) {
 	}
 
 	log := mongo.NewMongoLog()
-	log.Instance = config.AppConfig.Server.Name
+	log.Instance = config.AppConfig().Server.Name
 	log.HostIp = utils.HostIp()
 	log.Content = msg
 	log.Level = int32(_WARN)
@@ -155,7 +157,7 @@ func (logger *mongoLogger) Warn(msg string) {
 	log.ClassName = fileName
 	log.Stacktrace = fmt.Sprintf("%s\n\t%s:%d", methodName, fullFileName, lineNum)
 
-	go func() {
+	logger.async(func() {
 		defer func() {
 			if err := recover(); err != nil {
 				fmt.Println(log.Content)
@@ -173,7 +175,7 @@ func (logger *mongoLogger) Warn(msg string) {
 			fmt.Println(log.Stacktrace)
 			fmt.Println(err.Error())
 		}
-	}()
+	})
 }
 
 // 错误日志
@@ -187,7 +189,7 @@ func (logger *mongoLogger) Error(err error) {
 	}
 
 	log := mongo.NewMongoLog()
-	log.Instance = config.AppConfig.Server.Name
+	log.Instance = config.AppConfig().Server.Name
 	log.HostIp = utils.HostIp()
 	log.Content = err.Error()
 	log.Level = int32(_ERROR)
@@ -248,7 +250,7 @@ func (logger *mongoLogger) Error(err error) {
 		log.Stacktrace = fmt.Sprintf("%s\n\t%s:%d", methodName, fullFileName, lineNum)
 	}
 
-	go func() {
+	logger.async(func() {
 		defer func() {
 			if err := recover(); err != nil {
 				fmt.Println(log.Content)
@@ -266,5 +268,26 @@ func (logger *mongoLogger) Error(err error) {
 			fmt.Println(log.Stacktrace)
 			fmt.Println(err.Error())
 		}
+	})
+}
+
+// 异步写入日志, 停机完成后同步写入
+func (logger *mongoLogger) async(write func()) {
+	if !logger.inflight.Begin() {
+		write()
+		return
+	}
+
+	go func() {
+		defer logger.inflight.Done()
+		write()
 	}()
 }
+
+// 停机完成时等待异步写入的日志在截止时间前完成
+func (logger *mongoLogger) shutdown(event appcontext.AppEventShutdownCompleted) error {
+	if err := logger.inflight.Wait(event.Deadline); err != nil {
+		return fmt.Errorf("mongo logger: %s", err.Error())
+	}
+	return nil
+}
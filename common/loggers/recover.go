@@ -1,17 +1,44 @@
 package loggers
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+	"log"
+	"looklapi/common/appcontext"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	// 异步处理应用事件时的panic输出到日志
+	appcontext.SetPanicHandler(logRecovered)
+}
 
 func RecoverLog() {
 	if err := recover(); err != nil {
-		if throws, ok := err.(error); ok {
-			if _, ok := throws.(stackTracer); ok {
-				GetLogger().Error(throws)
-			} else {
-				GetLogger().Error(errors.WithStack(throws))
-			}
-		} else if msg, ok := err.(string); ok {
-			GetLogger().Error(errors.New(msg))
+		logRecovered(err)
+	}
+}
+
+// 输出recover的值
+func logRecovered(err interface{}) {
+	logger := GetLogger()
+	if logger == nil {
+		logger = GetConsoleLogger()
+	}
+	if logger == nil {
+		log.Println(fmt.Sprintf("%v %v", "ERROR", err))
+		return
+	}
+
+	if throws, ok := err.(error); ok {
+		if _, ok := throws.(stackTracer); ok {
+			logger.Error(throws)
+		} else {
+			logger.Error(errors.WithStack(throws))
 		}
+	} else if msg, ok := err.(string); ok {
+		logger.Error(errors.New(msg))
+	} else {
+		logger.Error(errors.New(fmt.Sprint(err)))
 	}
 }
@@ -22,7 +22,7 @@ const (
 	_DBNAME = "test"
 )
 
-var mongoUri = appConfig.AppConfig.MongodbUri
+var mongoUri = appConfig.AppConfig().MongodbUri
 var mongoClient *mongo.Client
 var clientInitialized = false
 
@@ -7,6 +7,6 @@ type RabbitMqExchange = string
 type RabbitMqRouteKey = string
 
 const (
-	LOG_LEVEL_CHANGE       RabbitMqExchange = "log_level_change"       // 日志等级变更交换器
 	MANUAL_SERVICE_REFRESH RabbitMqExchange = "manual_service_refresh" // 服务配置刷新交换器
+	APP_EVENT              RabbitMqExchange = "app_event"              // 分布式应用事件交换器, 按服务名称区分
 )
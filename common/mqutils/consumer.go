@@ -10,14 +10,21 @@ import (
 	appConfig "looklapi/config"
 	"looklapi/errs"
 	"reflect"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/streadway/amqp"
 )
 
 type consumerBinder struct {
 	workqueueReconnectCh chan *consumer
 	broadcastReconnectCh chan *consumer
+
+	inflight  appcontext.Inflight   // 处理中的消息
+	mu        *sync.Mutex           // 消费者管理锁
+	consumers map[*mqChannel]string // 消费通道 -> consumer tag
 }
 
 // received app event and process.
@@ -110,11 +117,12 @@ func (binder *consumerBinder) Subscribe() {
 }
 
 func init() {
-	if utils.IsEmpty(appConfig.AppConfig.Rabbitmq.Address) {
+	if utils.IsEmpty(appConfig.AppConfig().Rabbitmq.Address) {
 		return
 	}
-	binder := &consumerBinder{}
+	binder := &consumerBinder{mu: &sync.Mutex{}, consumers: make(map[*mqChannel]string)}
 	binder.Subscribe()
+	appcontext.OnWithErr(binder.shutdown, 0)
 }
 
 // 绑定消费者
@@ -172,7 +180,8 @@ func (binder *consumerBinder) bindWorkQueueConsumer(consumer *consumer) bool {
 		return false
 	}
 
-	deliverCh, err := recChan.Channel.Consume(consumer.RouteKey, "", false, false, false, false, nil)
+	tag := consumerTag()
+	deliverCh, err := recChan.Channel.Consume(consumer.RouteKey, tag, false, false, false, false, nil)
 	if err != nil {
 		loggers.GetLogger().Error(err)
 		return false
@@ -185,15 +194,35 @@ func (binder *consumerBinder) bindWorkQueueConsumer(consumer *consumer) bool {
 		defer loggers.RecoverLog()
 		for {
 			select {
-			case delivery := <-deliverCh:
+			case delivery, ok := <-deliverCh:
+				if !ok {
+					// 停机取消消费后退出, 否则等待通道关闭后重连
+					if binder.inflight.Stopped() {
+						return
+					}
+					deliverCh = nil
+					continue
+				}
+				if !binder.inflight.Begin() {
+					delivery.Nack(false, true)
+					continue
+				}
 				if consumer.Parallel && consumer.PrefetchCount > 1 {
-					go consume(&delivery, consumer)
+					go func() {
+						defer binder.inflight.Done()
+						consume(&delivery, consumer)
+					}()
 				} else {
 					consume(&delivery, consumer)
+					binder.inflight.Done()
 				}
 			case err := <-errChan:
-				loggers.GetLogger().Error(err)
+				binder.untrack(recChan)
 				removeConsumerChannel(recChan)
+				if binder.inflight.Stopped() {
+					return
+				}
+				loggers.GetLogger().Error(err)
 				// bindWorkQueueConsumer(consumer)
 				binder.workqueueReconnectCh <- consumer
 				return
@@ -202,6 +231,7 @@ func (binder *consumerBinder) bindWorkQueueConsumer(consumer *consumer) bool {
 	}()
 
 	addConsumerChannel(recChan)
+	binder.track(recChan, tag)
 	return true
 }
 
@@ -244,7 +274,8 @@ func (binder *consumerBinder) bindBroadcastConsumer(consumer *consumer) bool {
 		return false
 	}
 
-	deliverCh, err := recChan.Channel.Consume(queue.Name, "", false, true, false, false, nil)
+	tag := consumerTag()
+	deliverCh, err := recChan.Channel.Consume(queue.Name, tag, false, true, false, false, nil)
 	if err != nil {
 		loggers.GetLogger().Error(err)
 		return false
@@ -257,11 +288,28 @@ func (binder *consumerBinder) bindBroadcastConsumer(consumer *consumer) bool {
 		defer loggers.RecoverLog()
 		for {
 			select {
-			case delivery := <-deliverCh:
+			case delivery, ok := <-deliverCh:
+				if !ok {
+					// 停机取消消费后退出, 否则等待通道关闭后重连
+					if binder.inflight.Stopped() {
+						return
+					}
+					deliverCh = nil
+					continue
+				}
+				if !binder.inflight.Begin() {
+					delivery.Nack(false, true)
+					continue
+				}
 				consume(&delivery, consumer)
+				binder.inflight.Done()
 			case err := <-errChan:
-				loggers.GetLogger().Error(err)
+				binder.untrack(recChan)
 				removeConsumerChannel(recChan)
+				if binder.inflight.Stopped() {
+					return
+				}
+				loggers.GetLogger().Error(err)
 				// bindBroadcastConsumer(consumer)
 				binder.broadcastReconnectCh <- consumer
 				return
@@ -270,9 +318,56 @@ func (binder *consumerBinder) bindBroadcastConsumer(consumer *consumer) bool {
 	}()
 
 	addConsumerChannel(recChan)
+	binder.track(recChan, tag)
 	return true
 }
 
+// 记录消费通道, 停机时取消消费
+func (binder *consumerBinder) track(ch *mqChannel, tag string) {
+	binder.mu.Lock()
+	defer binder.mu.Unlock()
+
+	binder.consumers[ch] = tag
+}
+
+// 移除已关闭的消费通道
+func (binder *consumerBinder) untrack(ch *mqChannel) {
+	binder.mu.Lock()
+	defer binder.mu.Unlock()
+
+	delete(binder.consumers, ch)
+}
+
+// 停机时取消所有消费者, 并等待处理中的消息在截止时间前完成
+func (binder *consumerBinder) shutdown(event appcontext.AppEventShutdownRequested) error {
+	binder.inflight.Stop()
+
+	binder.mu.Lock()
+	consumers := make(map[*mqChannel]string, len(binder.consumers))
+	for ch, tag := range binder.consumers {
+		consumers[ch] = tag
+	}
+	binder.mu.Unlock()
+
+	for ch, tag := range consumers {
+		if err := ch.Channel.Cancel(tag, false); err != nil {
+			loggers.GetLogger().Error(err)
+		}
+	}
+
+	if err := binder.inflight.Wait(event.Deadline); err != nil {
+		return fmt.Errorf("mq consumers: %s", err.Error())
+	}
+	loggers.GetLogger().Info("mq consumers stopped")
+	return nil
+}
+
+// 生成consumer tag
+func consumerTag() string {
+	uid, _ := uuid.NewV4()
+	return strings.ReplaceAll(uid.String(), "-", "")
+}
+
 func consume(delivery *amqp.Delivery, consumer *consumer) {
 	content := string(delivery.Body)
 	if consumer.onReceived(content) {
@@ -0,0 +1,90 @@
+package mqutils
+
+import (
+	"errors"
+	"fmt"
+	"looklapi/common/appcontext"
+	"looklapi/common/loggers"
+	"looklapi/common/utils"
+	appConfig "looklapi/config"
+	"reflect"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// 分布式应用事件
+type appEventMessage struct {
+	Origin  string // 发布事件的实例
+	Name    string // 事件名称
+	Content string // 事件内容json
+}
+
+// 分布式应用事件传输层, 通过广播交换器在集群的所有实例间传递事件
+type appEventTransport struct {
+	origin   string // 本实例标识
+	exchange string // 广播交换器
+}
+
+func init() {
+	if utils.IsEmpty(appConfig.AppConfig().Rabbitmq.Address) {
+		return
+	}
+
+	uid, _ := uuid.NewV4()
+	transport := &appEventTransport{
+		origin:   strings.ReplaceAll(uid.String(), "-", ""),
+		exchange: APP_EVENT + "." + appConfig.AppConfig().Server.Name,
+	}
+	NewBroadcastConsumer(transport.exchange, 5, reflect.TypeOf(&appEventMessage{}), transport.receive)
+	appcontext.SetEventTransport(transport.send)
+}
+
+// 广播事件
+func (transport *appEventTransport) send(name string, event interface{}) error {
+	msg := &appEventMessage{
+		Origin:  transport.origin,
+		Name:    name,
+		Content: utils.StructToJson(event),
+	}
+	if !PubBroadcastMsg(transport.exchange, msg) {
+		return errors.New("publish to " + transport.exchange + " failed")
+	}
+	return nil
+}
+
+// 接收其他实例的事件并在本实例发布, 忽略本实例发布的事件
+func (transport *appEventTransport) receive(msg interface{}) bool {
+	eventMsg := msg.(*appEventMessage)
+	if eventMsg.Origin == transport.origin {
+		return true
+	}
+
+	etype, ok := appcontext.DistributedEventType(eventMsg.Name)
+	if !ok {
+		loggers.GetLogger().Warn(fmt.Sprintf("distributed event %s is not registered", eventMsg.Name))
+		return true
+	}
+
+	isptr := etype.Kind() == reflect.Ptr
+	tp := etype
+	if isptr {
+		tp = tp.Elem()
+	}
+	ptr := reflect.New(tp)
+	if err := utils.JsonToStruct(eventMsg.Content, ptr.Interface()); err != nil {
+		loggers.GetLogger().Error(err)
+		return true
+	}
+
+	event := ptr.Interface()
+	if !isptr {
+		event = ptr.Elem().Interface()
+	}
+
+	// 观察者处理失败时不重试, 避免重复处理
+	if err := appcontext.GetAppEventPublisher().DeliverEvent(event); err != nil {
+		loggers.GetLogger().Error(err)
+	}
+	return true
+}
@@ -9,7 +9,7 @@ import (
 
 // 发布工作队列消息
 func PubWorkQueueMsg(routeKey string, msg interface{}) bool {
-	if utils.IsEmpty(appConfig.AppConfig.Rabbitmq.Address) {
+	if utils.IsEmpty(appConfig.AppConfig().Rabbitmq.Address) {
 		loggers.GetLogger().Warn("mq is not enabled")
 		return false
 	}
@@ -34,7 +34,7 @@ func PubWorkQueueMsg(routeKey string, msg interface{}) bool {
 
 // 发布广播消息
 func PubBroadcastMsg(exchange string, msg interface{}) bool {
-	if utils.IsEmpty(appConfig.AppConfig.Rabbitmq.Address) {
+	if utils.IsEmpty(appConfig.AppConfig().Rabbitmq.Address) {
 		loggers.GetLogger().Warn("mq is not enabled")
 		return false
 	}
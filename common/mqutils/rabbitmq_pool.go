@@ -17,12 +17,12 @@ var _rabbitmqConnPool *rabbitmqConnPool // mq连接池
 var _idlClearTask *cron.Cron            // 空闲连接清理任务
 
 func init() {
-	if utils.IsEmpty(appConfig.AppConfig.Rabbitmq.Address) {
+	if utils.IsEmpty(appConfig.AppConfig().Rabbitmq.Address) {
 		return
 	}
 
 	_rabbitmqConnPool = &rabbitmqConnPool{
-		connStr: appConfig.AppConfig.Rabbitmq.Address,
+		connStr: appConfig.AppConfig().Rabbitmq.Address,
 
 		pubConns:      make(map[string]*rabbitMqConnData),
 		pubChs:        make([]*mqChannel, 0, _chLimitForConn),
@@ -2,6 +2,7 @@ package redisutils
 
 import (
 	"github.com/garyburd/redigo/redis"
+	"looklapi/common/appcontext"
 	"looklapi/common/utils"
 	"looklapi/config"
 	"regexp"
@@ -11,8 +12,6 @@ import (
 	"time"
 )
 
-var address = config.AppConfig.Redis.Host + ":" + config.AppConfig.Redis.Port
-var pwdOption = redis.DialPassword(config.AppConfig.Redis.Password)
 var redisPool = &sync.Map{}
 
 const (
@@ -59,7 +58,34 @@ func newPool(db uint8) *redis.Pool {
 		IdleTimeout: 300 * time.Second, //连接关闭时间 300秒 （300秒不使用自动关闭）
 		Wait:        true,
 		Dial: func() (redis.Conn, error) { //要连接的redis数据库
+			cfg := config.AppConfig()
+			address := cfg.Redis.Host + ":" + cfg.Redis.Port
+			pwdOption := redis.DialPassword(cfg.Redis.Password)
 			return redis.Dial(_NETWORK, address, pwdOption, redis.DialDatabase(int(db)))
 		},
 	}
 }
+
+// redis连接池管理
+type poolManager struct {
+}
+
+func init() {
+	manager := &poolManager{}
+	appcontext.On(func(event appcontext.AppEventConfigChanged) {
+		if event.Changed("redis") {
+			manager.resetPools()
+		}
+	})
+}
+
+// 关闭并移除已有连接池, 新连接使用最新配置创建
+func (manager *poolManager) resetPools() {
+	redisPool.Range(func(key, value interface{}) bool {
+		redisPool.Delete(key)
+		if pool, ok := value.(*redis.Pool); ok {
+			pool.Close()
+		}
+		return true
+	})
+}
@@ -41,6 +41,20 @@ func register() {
 	loggers.GetLogger().Info("consul register success")
 }
 
+// 注销服务
+func deregister() {
+	if serviceRegistry == nil || serviceRegistry.client == nil || serviceRegistry.registration == nil {
+		return
+	}
+
+	if err := serviceRegistry.client.Agent().ServiceDeregister(serviceRegistry.registration.ID); err != nil {
+		loggers.GetLogger().Error(err)
+		return
+	}
+
+	loggers.GetLogger().Info("consul deregister success")
+}
+
 // 获取所有健康服务
 func getAllHealthServices() (map[string][]*consulApi.AgentService, error) {
 	serviceMap, err := serviceRegistry.client.Agent().Services()
@@ -64,17 +78,18 @@ func getAllHealthServices() (map[string][]*consulApi.AgentService, error) {
 }
 
 func generateAgentServiceRegistration() *consulApi.AgentServiceRegistration {
+	cfg := appConfig.AppConfig()
 	registration := new(consulApi.AgentServiceRegistration)
 	registration.ID = fmt.Sprintf("%s-%s-%s",
-		appConfig.AppConfig.Server.Name,
+		cfg.Server.Name,
 		strings.ReplaceAll(utils.HostIp(), ".", "-"),
-		appConfig.AppConfig.Server.Port)
-	registration.Name = appConfig.AppConfig.Server.Name
+		cfg.Server.Port)
+	registration.Name = cfg.Server.Name
 	registration.Address = utils.HostIp()
-	registration.Port, _ = strconv.Atoi(appConfig.AppConfig.Server.Port)
+	registration.Port, _ = strconv.Atoi(cfg.Server.Port)
 
 	var tags []string
-	if appConfig.AppConfig.Consul.Secure {
+	if cfg.Consul.Secure {
 		tags = append(tags, "secure=true")
 	} else {
 		tags = append(tags, "secure=false")
@@ -87,25 +102,27 @@ func generateAgentServiceRegistration() *consulApi.AgentServiceRegistration {
 }
 
 func generateHealthCheck(address string, port int) *consulApi.AgentServiceCheck {
+	cfg := appConfig.AppConfig()
 	check := new(consulApi.AgentServiceCheck)
 	schema := "http"
-	if appConfig.AppConfig.Consul.Secure {
+	if cfg.Consul.Secure {
 		schema = "https"
 	}
 	check.HTTP = fmt.Sprintf("%s://%s:%d/%s",
 		schema, address, port,
-		strings.TrimLeft(appConfig.AppConfig.Consul.HealthCheck, "/"))
+		strings.TrimLeft(cfg.Consul.HealthCheck, "/"))
 
-	check.Timeout = appConfig.AppConfig.Consul.Timeout
-	check.Interval = appConfig.AppConfig.Consul.Interval
-	check.DeregisterCriticalServiceAfter = appConfig.AppConfig.Consul.DeregisterCriticalServiceAfter // 故障检查失败后 consul自动将注册服务删除
+	check.Timeout = cfg.Consul.Timeout
+	check.Interval = cfg.Consul.Interval
+	check.DeregisterCriticalServiceAfter = cfg.Consul.DeregisterCriticalServiceAfter // 故障检查失败后 consul自动将注册服务删除
 
 	return check
 }
 
 func generateConsulConfig() *consulApi.Config {
+	cfg := appConfig.AppConfig()
 	consulConfig := consulApi.DefaultConfig()
-	consulConfig.Address = appConfig.AppConfig.Consul.Host + ":" + appConfig.AppConfig.Consul.Port
+	consulConfig.Address = cfg.Consul.Host + ":" + cfg.Consul.Port
 	consulConfig.Scheme = "http"
 
 	return consulConfig
@@ -30,7 +30,7 @@ type serviceManager struct {
 
 func init() {
 	svManager = &serviceManager{
-		hostEndpoint: fmt.Sprintf("%s:%s", utils.HostIp(), appConfig.AppConfig.Server.Port),
+		hostEndpoint: fmt.Sprintf("%s:%s", utils.HostIp(), appConfig.AppConfig().Server.Port),
 		updateTask:   cron.New(cron.WithSeconds()),
 	}
 	svManager.Subscribe()
@@ -39,12 +39,25 @@ func init() {
 // received app event and process.
 // for event publish well, the developers must deal with the panic by their self
 func (manager *serviceManager) OnApplicationEvent(event interface{}) {
-	manager.initialize()
+	switch event := event.(type) {
+	case appcontext.AppEventBeanInjected:
+		manager.initialize()
+	case appcontext.AppEventConfigChanged:
+		if event.Changed("consul") || event.Changed("server") {
+			manager.reRegister()
+		}
+	case appcontext.AppEventShutdownRequested:
+		manager.shutdown()
+	}
 }
 
 // register to the application event publisher
 func (manager *serviceManager) Subscribe() {
-	appcontext.GetAppEventPublisher().Subscribe(manager, reflect.TypeOf(appcontext.AppEventBeanInjected(0)))
+	// 先于mq消费者及定时任务完成服务注册
+	appcontext.GetAppEventPublisher().SubscribeWithPriority(manager, reflect.TypeOf(appcontext.AppEventBeanInjected(0)), -10)
+	appcontext.GetAppEventPublisher().Subscribe(manager, reflect.TypeOf(appcontext.AppEventConfigChanged{}))
+	// 停机时先注销服务, 停止接收新的请求
+	appcontext.GetAppEventPublisher().SubscribeWithPriority(manager, reflect.TypeOf(appcontext.AppEventShutdownRequested{}), -10)
 }
 
 // 获取服务管理器
@@ -58,7 +71,7 @@ func (manager *serviceManager) initialize() {
 		return
 	}
 
-	if utils.IsEmpty(appConfig.AppConfig.Consul.Host) || utils.IsEmpty(appConfig.AppConfig.Consul.Port) {
+	if cfg := appConfig.AppConfig(); utils.IsEmpty(cfg.Consul.Host) || utils.IsEmpty(cfg.Consul.Port) {
 		// service discovery is not enabled
 		manager.isReady = true
 		return
@@ -88,6 +101,34 @@ func (manager *serviceManager) initialize() {
 	manager.isReady = true
 }
 
+// 配置变更后重新注册服务
+func (manager *serviceManager) reRegister() {
+	defer loggers.RecoverLog()
+
+	if !manager.isReady || serviceRegistry == nil {
+		return
+	}
+
+	cfg := appConfig.AppConfig()
+	manager.hostEndpoint = fmt.Sprintf("%s:%s", utils.HostIp(), cfg.Server.Port)
+	manager.isCutoff = slices.Contains(manager.cutoffCache, manager.hostEndpoint)
+
+	deregister()
+	if utils.IsEmpty(cfg.Consul.Host) || utils.IsEmpty(cfg.Consul.Port) {
+		// service discovery is disabled
+		manager.updateTask.Stop()
+		return
+	}
+	register()
+	manager.updateTask.Start()
+}
+
+// 停机时停止服务更新任务并从consul注销服务
+func (manager *serviceManager) shutdown() {
+	<-manager.updateTask.Stop().Done()
+	deregister()
+}
+
 // 更新服务配置
 func (manager *serviceManager) UpdateManualService(manualService *modelimpl.ManualService) {
 	cutoff := make([]string, 0)
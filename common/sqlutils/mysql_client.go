@@ -9,7 +9,7 @@ import (
 	"xorm.io/xorm/names"
 )
 
-var mysqlConf = appConfig.AppConfig.MySql
+var mysqlConf = appConfig.AppConfig().MySql
 var xormMySqlEngine *xorm.Engine
 
 func init() {
@@ -24,7 +24,7 @@ func init() {
 	engine.SetMaxIdleConns(2)
 	engine.SetColumnMapper(names.SnakeMapper{})
 	engine.SetTableMapper(names.SnakeMapper{})
-	if appConfig.AppConfig.Profile == "dev" {
+	if appConfig.IsProfileActive("dev") {
 		engine.ShowSQL(true)
 	}
 	xormMySqlEngine = engine
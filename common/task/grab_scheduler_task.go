@@ -25,17 +25,22 @@ type GrabSchedulerTask interface {
 
 // task manager
 type grabSchedulerTaskManager struct {
-	init bool
+	init     bool
+	tasks    []GrabSchedulerTask `wired:"Autowired"` // 所有绑定的任务, 按优先级排列
+	inflight appcontext.Inflight // 执行中的任务
 }
 
 func init() {
 	manager := &grabSchedulerTaskManager{}
+	wireutils.BindAs[*grabSchedulerTaskManager](manager, false, 1)
 	manager.Subscribe()
+	appcontext.OnWithErr(manager.shutdown, 10)
 }
 
 // register to the application event publisher
 func (manager *grabSchedulerTaskManager) Subscribe() {
-	appcontext.GetAppEventPublisher().Subscribe(manager, reflect.TypeOf(appcontext.AppEventBeanInjected(0)))
+	// 在服务注册及mq消费者绑定完成后启动任务
+	appcontext.GetAppEventPublisher().SubscribeWithPriority(manager, reflect.TypeOf(appcontext.AppEventBeanInjected(0)), 10)
 }
 
 // received app event and process.
@@ -51,17 +56,21 @@ func (manager *grabSchedulerTaskManager) OnApplicationEvent(event interface{}) {
 		}
 	}()
 
-	for _, task := range wireutils.ResovleAll(reflect.TypeOf((*GrabSchedulerTask)(nil)).Elem()) {
-		if task, ok := task.(GrabSchedulerTask); ok {
-			task.StartTask(manager.wrapper(task))
-		}
+	for _, task := range manager.tasks {
+		task.StartTask(manager.wrapper(task))
 	}
 	manager.init = true
 }
 
 func (manager *grabSchedulerTaskManager) wrapper(task GrabSchedulerTask) func() {
 	return func() {
+		// 停机后不再执行任务
+		if !manager.inflight.Begin() {
+			return
+		}
+		defer manager.inflight.Done()
 		defer loggers.RecoverLog()
+
 		grab := manager.grab(task)
 		if grab {
 			task.Executor()()
@@ -69,6 +78,14 @@ func (manager *grabSchedulerTaskManager) wrapper(task GrabSchedulerTask) func()
 	}
 }
 
+// 停机时停止执行新任务, 并等待执行中的任务在截止时间前完成
+func (manager *grabSchedulerTaskManager) shutdown(event appcontext.AppEventShutdownRequested) error {
+	if err := manager.inflight.Wait(event.Deadline); err != nil {
+		return fmt.Errorf("grab scheduler tasks: %s", err.Error())
+	}
+	return nil
+}
+
 // grab the hold key
 func (manager *grabSchedulerTaskManager) grab(task GrabSchedulerTask) bool {
 	doing := false
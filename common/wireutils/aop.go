@@ -0,0 +1,125 @@
+package wireutils
+
+import (
+	"reflect"
+)
+
+// 方法调用
+type Invocation struct {
+	Target  interface{}   // 被代理的下一层实例
+	Method  string        // 方法名称
+	Args    []interface{} // 方法参数
+	Results []interface{} // 除error外的返回值
+	Err     error         // 方法最后一个返回值为error时的错误
+
+	index        int
+	interceptors []Interceptor
+	call         func(inv *Invocation)
+}
+
+// 调用下一个拦截器, 最后一个拦截器调用被代理的方法
+// 可多次调用, 如实现重试
+func (inv *Invocation) Proceed() {
+	index := inv.index
+	defer func() {
+		inv.index = index
+	}()
+
+	if index < len(inv.interceptors) {
+		inv.index = index + 1
+		inv.interceptors[index](inv)
+		return
+	}
+	inv.call(inv)
+}
+
+// 第i个参数, 不存在时返回nil
+func (inv *Invocation) Arg(i int) interface{} {
+	if i < 0 || i >= len(inv.Args) {
+		return nil
+	}
+	return inv.Args[i]
+}
+
+// 第i个返回值, 不存在时返回nil, 拦截器未调用Proceed时返回值可能未设置
+func (inv *Invocation) Result(i int) interface{} {
+	if i < 0 || i >= len(inv.Results) {
+		return nil
+	}
+	return inv.Results[i]
+}
+
+// 方法拦截器, 须调用inv.Proceed()执行被代理的方法
+type Interceptor func(inv *Invocation)
+
+// 方法执行前调用
+func Before(fn func(inv *Invocation)) Interceptor {
+	return func(inv *Invocation) {
+		fn(inv)
+		inv.Proceed()
+	}
+}
+
+// 方法执行后调用, 可读取或修改返回值及错误
+func After(fn func(inv *Invocation)) Interceptor {
+	return func(inv *Invocation) {
+		inv.Proceed()
+		fn(inv)
+	}
+}
+
+// 环绕方法执行, 由fn决定何时及是否调用inv.Proceed()
+func Around(fn func(inv *Invocation)) Interceptor {
+	return Interceptor(fn)
+}
+
+// 为itype类型注册方法拦截器, 按注册顺序由外到内执行
+// 拦截器由代理中注入的 *Advisor 执行, 代理可通过 cmd/wiregen 生成
+func (c *Container) Intercept(itype reflect.Type, interceptors ...Interceptor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.interceptors == nil {
+		c.interceptors = make(map[reflect.Type][]Interceptor)
+	}
+	c.interceptors[itype] = append(c.interceptors[itype], interceptors...)
+}
+
+// 类型的拦截器, 没有注册时使用父容器的拦截器
+func (c *Container) interceptorsOf(itype reflect.Type) []Interceptor {
+	for cur := c; cur != nil; cur = cur.parent {
+		cur.mu.Lock()
+		interceptors := cur.interceptors[itype]
+		cur.mu.Unlock()
+		if len(interceptors) > 0 {
+			return interceptors
+		}
+	}
+	return nil
+}
+
+var advisorType = reflect.TypeOf((*Advisor)(nil))
+
+// 拦截器执行器
+// 代理中 `wired:"Autowired"` 的 *Advisor 字段由容器注入, 按代理的绑定类型执行容器中注册的拦截器
+type Advisor struct {
+	container *Container
+	itype     reflect.Type
+}
+
+// 通过拦截器链调用方法
+// target 被代理的下一层实例, call 调用target的方法并设置Results及Err
+func (advisor *Advisor) Invoke(target interface{}, method string, args []interface{}, call func(inv *Invocation)) *Invocation {
+	inv := &Invocation{
+		Target: target,
+		Method: method,
+		Args:   args,
+		call:   call,
+	}
+	if advisor != nil && advisor.container != nil {
+		inv.interceptors = advisor.container.interceptorsOf(advisor.itype)
+	}
+
+	inv.Proceed()
+	return inv
+}
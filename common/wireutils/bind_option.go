@@ -0,0 +1,11 @@
+package wireutils
+
+// 绑定选项
+type BindOption func(model *wiredModel)
+
+// 指定绑定名称, 配合 `wired:"Autowired,name=xxx"` 注入指定的实例
+func Named(name string) BindOption {
+	return func(model *wiredModel) {
+		model.name = name
+	}
+}
@@ -0,0 +1,102 @@
+package wireutils
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// 是否为集合注入字段
+// []T 按优先级注入T类型的所有绑定, map[string]T 以绑定名称为键注入, 未指定名称时为实例类型名称
+func isCollection(ftyp reflect.Type) bool {
+	switch ftyp.Kind() {
+	case reflect.Slice:
+		return collectable(ftyp.Elem())
+	case reflect.Map:
+		return ftyp.Key().Kind() == reflect.String && collectable(ftyp.Elem())
+	default:
+		return false
+	}
+}
+
+// 集合元素须为接口或结构体指针
+func collectable(etyp reflect.Type) bool {
+	return etyp.Kind() == reflect.Interface || (etyp.Kind() == reflect.Ptr && etyp.Elem().Kind() == reflect.Struct)
+}
+
+// 集合注入的绑定及其键, 按优先级排列
+// 存在代理时, 代理链的最外层替代其包装的实例, 键不变, 集合所在实例不注入自身
+func collectionCandidates(model *wiredModel, etyp reflect.Type) ([]*wiredModel, []string) {
+	var outer *wiredModel
+	var impls []*wiredModel
+	for _, child := range model.owner.lookup(etyp) {
+		if child.proxy {
+			if outer == nil {
+				outer = child
+			}
+			continue
+		}
+		impls = append(impls, child)
+	}
+
+	var candidates []*wiredModel
+	var keys []string
+	for i, child := range impls {
+		if child == model {
+			continue
+		}
+		keys = append(keys, collectionKey(child))
+		if i == 0 && outer != nil && model.metaType != etyp {
+			child = outer
+		}
+		candidates = append(candidates, child)
+	}
+	return candidates, keys
+}
+
+// 集合中的键, 绑定名称或实例类型名称
+func collectionKey(model *wiredModel) string {
+	if model.name != "" {
+		return model.name
+	}
+	return beanName(model)
+}
+
+// 创建注入的集合, 没有绑定时为空集合
+func injectCollection(model *wiredModel, ftyp reflect.Type) (reflect.Value, error) {
+	// 构造函数注册的实例先完成构造, 以实例类型名称作为键
+	for _, child := range model.owner.lookup(ftyp.Elem()) {
+		if child.scope == ScopeSingleton && child.target == nil && child.provider.IsValid() {
+			if err := construct(child); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+	}
+
+	candidates, keys := collectionCandidates(model, ftyp.Elem())
+	instances := make([]interface{}, len(candidates))
+	for i, child := range candidates {
+		ins, err := obtain(child, model.ctx)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		instances[i] = ins
+	}
+
+	if ftyp.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(ftyp, 0, len(instances))
+		for _, ins := range instances {
+			slice = reflect.Append(slice, reflect.ValueOf(ins))
+		}
+		return slice, nil
+	}
+
+	collection := reflect.MakeMapWithSize(ftyp, len(instances))
+	for i, ins := range instances {
+		key := reflect.ValueOf(keys[i]).Convert(ftyp.Key())
+		if collection.MapIndex(key).IsValid() {
+			return reflect.Value{}, fmt.Errorf("duplicated key %s of type %s", key.String(), typeName(ftyp.Elem()))
+		}
+		collection.SetMapIndex(key, reflect.ValueOf(ins))
+	}
+	return collection, nil
+}
@@ -0,0 +1,138 @@
+package wireutils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// 绑定条件, 在Inject时评估, 不满足条件的绑定从容器中移除
+type condition struct {
+	desc    string       // 条件描述
+	missing reflect.Type // OnMissingBinding 的类型
+	match   func() bool
+}
+
+// 指定profile激活时绑定, 多个profile满足其一即可
+// 以!开头表示profile未激活时绑定, 如 OnProfile("!prod")
+func OnProfile(profiles ...string) BindOption {
+	return func(model *wiredModel) {
+		model.conditions = append(model.conditions, &condition{
+			desc: fmt.Sprintf("profile %s", strings.Join(profiles, ",")),
+			match: func() bool {
+				for _, profile := range profiles {
+					if strings.HasPrefix(profile, "!") {
+						if !properties().IsProfileActive(profile[1:]) {
+							return true
+						}
+					} else if properties().IsProfileActive(profile) {
+						return true
+					}
+				}
+				return false
+			},
+		})
+	}
+}
+
+// 配置项存在时绑定, 如 OnProperty("redis.host")
+// 指定values时配置值须与其一相同(忽略大小写), 未指定时配置值不能为空或false
+func OnProperty(key string, values ...string) BindOption {
+	return func(model *wiredModel) {
+		model.conditions = append(model.conditions, &condition{
+			desc: fmt.Sprintf("property %s", key),
+			match: func() bool {
+				val, ok := properties().Lookup(key)
+				if !ok {
+					return false
+				}
+
+				val = strings.TrimSpace(val)
+				if len(values) == 0 {
+					return val != "" && !strings.EqualFold(val, "false")
+				}
+				for _, expect := range values {
+					if strings.EqualFold(val, expect) {
+						return true
+					}
+				}
+				return false
+			},
+		})
+	}
+}
+
+// T类型没有其他绑定时绑定, 用于提供默认实现
+// 带有该条件的绑定作为默认实现, 不计入其他绑定的判断
+//
+//	wireutils.BindAs[Cache](&memoryCache{}, false, 9, wireutils.OnMissingBinding[Cache]())
+//	wireutils.BindAs[Cache](&redisCache{}, false, 1, wireutils.OnProperty("redis.host"))
+func OnMissingBinding[T any]() BindOption {
+	return func(model *wiredModel) {
+		itype := typeOf[T]()
+		model.conditions = append(model.conditions, &condition{
+			desc:    fmt.Sprintf("missing binding %s", typeName(itype)),
+			missing: itype,
+		})
+	}
+}
+
+// 评估绑定条件, 移除不满足条件的绑定
+// 先评估profile及配置项条件, 再以剩余的绑定评估OnMissingBinding条件
+func (c *Container) applyConditions() {
+	c.removeIf(func(model *wiredModel) bool {
+		for _, cond := range model.conditions {
+			if cond.missing == nil && !cond.match() {
+				return true
+			}
+		}
+		return false
+	})
+
+	var fallbacks = make(map[*wiredModel]bool)
+	for _, slice := range c.bindings {
+		for _, model := range slice {
+			for _, cond := range model.conditions {
+				if cond.missing == nil {
+					continue
+				}
+				for _, exist := range c.lookup(cond.missing) {
+					if exist != model && !isFallback(exist) {
+						fallbacks[model] = true
+					}
+				}
+			}
+		}
+	}
+	c.removeIf(func(model *wiredModel) bool {
+		return fallbacks[model]
+	})
+}
+
+// 是否为OnMissingBinding条件的默认实现
+func isFallback(model *wiredModel) bool {
+	for _, cond := range model.conditions {
+		if cond.missing != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// 从容器中移除满足条件的绑定
+func (c *Container) removeIf(remove func(model *wiredModel) bool) {
+	for itype, slice := range c.bindings {
+		var kept []*wiredModel
+		for _, model := range slice {
+			if !remove(model) {
+				kept = append(kept, model)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(c.bindings, itype)
+		} else {
+			c.bindings[itype] = kept
+		}
+	}
+}
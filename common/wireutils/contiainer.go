@@ -1,20 +1,48 @@
 package wireutils
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"sort"
-	"strings"
 	"sync"
 	"unsafe"
 )
 
-var container = make(map[reflect.Type][]*wiredModel)
-var mu = &sync.Mutex{}
-var injected = false
+// 依赖注入容器
+// 包级函数使用默认容器, 测试中可通过New创建独立的容器, 或通过Child创建子容器替换部分实现
+type Container struct {
+	mu           *sync.Mutex
+	parent       *Container                     // 父容器
+	registered   map[reflect.Type][]*wiredModel // 注册的绑定
+	bindings     map[reflect.Type][]*wiredModel // 生效的绑定, Inject时移除不满足条件的绑定
+	injected     bool                           // 是否注入完成
+	injectOrder  []*wiredModel                  // 按依赖顺序排列的已注入实例
+	initialized  []interface{}                  // 已初始化的实例
+	shutdown     bool                           // 是否已销毁
+	interceptors map[reflect.Type][]Interceptor // 方法拦截器
+}
+
+// 创建独立的容器
+func New() *Container {
+	return &Container{
+		mu:         &sync.Mutex{},
+		registered: make(map[reflect.Type][]*wiredModel),
+		bindings:   make(map[reflect.Type][]*wiredModel),
+	}
+}
+
+// 创建子容器, 子容器中没有绑定的类型从父容器解析
+// 子容器中绑定的类型覆盖父容器中同类型的绑定
+func (c *Container) Child() *Container {
+	child := New()
+	child.parent = c
+	return child
+}
 
 // 映射接口实例
-func Bind(itype reflect.Type, target interface{}, proxy bool, priority int) {
+// opts 绑定选项, 如 Named("primary") 指定绑定名称
+func (c *Container) Bind(itype reflect.Type, target interface{}, proxy bool, priority int, opts ...BindOption) {
 	if target == nil {
 		panic("target must not be nil")
 	}
@@ -30,219 +58,560 @@ func Bind(itype reflect.Type, target interface{}, proxy bool, priority int) {
 		panic("target must be a struct pointer")
 	}
 
-	mu.Lock()
-	defer mu.Unlock()
-
 	wm := newWiredModel(itype, target, proxy, priority)
-	container[itype] = append(container[itype], wm)
+	for _, opt := range opts {
+		opt(wm)
+	}
 
-	if len(container[itype]) == 1 {
-		return
+	c.register(wm)
+}
+
+// 注册构造函数
+// constructor 构造函数, 返回值为 T 或 (T, error), 以T类型绑定
+// 参数从容器中解析, 在Inject时按依赖顺序调用, 构造失败时Inject返回错误
+func (c *Container) Provide(constructor interface{}, priority int, opts ...BindOption) {
+	if constructor == nil {
+		panic("constructor must not be nil")
 	}
 
-	targets := container[itype]
-	temp := make([]*wiredModel, 0)
+	cval := reflect.ValueOf(constructor)
+	ctype := cval.Type()
+	if ctype.Kind() != reflect.Func {
+		panic("constructor must be a func")
+	}
 
-	proxyIndex := -1
-	for i, t := range targets {
-		if t.proxy {
-			proxyIndex = i
-			break
-		}
+	errType := reflect.TypeOf((*error)(nil)).Elem()
+	if ctype.NumOut() < 1 || ctype.NumOut() > 2 || (ctype.NumOut() == 2 && ctype.Out(1) != errType) {
+		panic("constructor must return T or (T, error)")
+	}
+	if ctype.Out(0) == errType {
+		panic("constructor must return T or (T, error)")
 	}
-	//proxyIndex := linq.From(targets).IndexOf(func(item interface{}) bool {
-	//	return item.(*wiredModel).proxy
-	//})
 
-	if proxyIndex >= 0 {
-		temp = append(temp, targets[proxyIndex])
+	wm := newWiredModel(ctype.Out(0), nil, false, priority)
+	wm.provider = cval
+	for _, opt := range opts {
+		opt(wm)
 	}
 
-	sort.Slice(targets, func(i, j int) bool {
-		return targets[i].priority <= targets[j].priority
-	})
+	c.register(wm)
+}
 
-	for _, tg := range targets {
-		if tg.proxy {
-			continue
+// 使用impl替换itype的所有绑定, 通常用于在测试中注入mock
+// 须在Inject前调用, Reset后恢复原有的绑定
+func (c *Container) Override(itype reflect.Type, impl interface{}) {
+	if impl == nil {
+		panic("impl must not be nil")
+	}
+	if !reflect.TypeOf(impl).AssignableTo(itype) {
+		panic(fmt.Sprintf("%T does not implement %s", impl, typeName(itype)))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wm := newWiredModel(itype, impl, false, 0)
+	wm.owner = c
+	c.bindings[itype] = []*wiredModel{wm}
+}
+
+// 重置注入状态并恢复Override替换的绑定, 以便重新Inject
+// 构造函数注册的实例在重新Inject时重新构造, 不调用实例的PreDestroy
+func (c *Container) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.bindings = make(map[reflect.Type][]*wiredModel, len(c.registered))
+	for itype, models := range c.registered {
+		c.bindings[itype] = append([]*wiredModel{}, models...)
+		for _, model := range models {
+			model.injected = false
+			model.injecting = false
+			if model.provider.IsValid() {
+				model.target = nil
+				model.params = nil
+			}
 		}
+	}
+
+	c.injected = false
+	c.injectOrder = nil
+	c.initialized = nil
+	c.shutdown = false
+}
+
+// 注册绑定模型, 按优先级排序
+func (c *Container) register(wm *wiredModel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	itype := wm.metaType
+	if wm.name != "" {
+		for _, exist := range c.registered[itype] {
+			if exist.name == wm.name {
+				panic(fmt.Sprintf("duplicated binding name %s of type %s", wm.name, typeName(itype)))
+			}
+		}
+	}
 
-		temp = append(temp, tg)
+	if wm.scope != ScopeSingleton && !wm.provider.IsValid() {
+		panic(fmt.Sprintf("%s scope of type %s requires a constructor registered by Provide", wm.scope, typeName(itype)))
 	}
 
-	container[itype] = temp
+	wm.owner = c
+	c.registered[itype] = sortBindings(append(c.registered[itype], wm))
+	c.bindings[itype] = sortBindings(append(c.bindings[itype], wm))
+}
+
+// 代理在前, 按优先级由外到内排列, 其后为按优先级排列的实例
+func sortBindings(targets []*wiredModel) []*wiredModel {
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].proxy != targets[j].proxy {
+			return targets[i].proxy
+		}
+		return targets[i].priority < targets[j].priority
+	})
+	return targets
+}
+
+// 查找类型的绑定, 没有绑定时从父容器查找
+func (c *Container) lookup(itype reflect.Type) []*wiredModel {
+	for cur := c; cur != nil; cur = cur.parent {
+		if targets := cur.bindings[itype]; len(targets) > 0 {
+			return targets
+		}
+	}
+	return nil
 }
 
 // 获取对象
-func Resovle(itype reflect.Type) interface{} {
-	targets := resovle(itype)
-	return targets[0].target
+func (c *Container) Resovle(itype reflect.Type) interface{} {
+	targets := c.resovle(itype)
+	return instance(targets[0])
+}
+
+// 获取指定名称的对象
+func (c *Container) ResovleNamed(itype reflect.Type, name string) interface{} {
+	for _, t := range c.resovle(itype) {
+		if t.name == name {
+			return instance(t)
+		}
+	}
+
+	panic(fmt.Sprintf("can not resolve the type %s named %s", typeName(itype), name))
 }
 
 // 获取所有对象
-func ResovleAll(itype reflect.Type) []interface{} {
-	targets := resovle(itype)
+func (c *Container) ResovleAll(itype reflect.Type) []interface{} {
+	targets := c.resovle(itype)
 	var all = make([]interface{}, len(targets))
 	for i, t := range targets {
-		all[i] = t.target
+		all[i] = instance(t)
 	}
 
 	return all
 }
 
+// 按请求上下文获取对象, 请求作用域的实例在同一请求中只创建一次
+func (c *Container) ResovleScoped(ctx context.Context, itype reflect.Type) (interface{}, error) {
+	targets := c.lookup(itype)
+	if len(targets) <= 0 {
+		return nil, fmt.Errorf("can not resolve the type %s", typeName(itype))
+	}
+	return obtain(targets[0], ctx)
+}
+
 // 注入对象
-func Inject() {
-	if injected {
-		return
+// 注入前移除不满足绑定条件的绑定, 并构建完整的依赖图, 返回所有无法解析的依赖及构造函数循环依赖
+// 注入完成后按依赖顺序调用实现了Initializer的实例
+// 注入标签限定的名称不匹配, 构造或初始化失败时返回错误
+func (c *Container) Inject() error {
+	if c.injected {
+		return nil
 	}
 
-	for _, slice := range container {
+	c.applyConditions()
+	if err := c.checkProxyChains(); err != nil {
+		return err
+	}
+
+	if err := c.buildGraph().err(); err != nil {
+		return err
+	}
+
+	for _, slice := range c.bindings {
 		for _, wiredModel := range slice {
-			inject(wiredModel)
+			if err := inject(wiredModel); err != nil {
+				return err
+			}
 		}
 	}
 
-	injected = true
+	c.injected = true
+	return c.postConstruct()
 }
 
-func inject(model *wiredModel) {
-	if model.injected {
-		return
+func inject(model *wiredModel) error {
+	if model.injected || model.injecting {
+		return nil
+	}
+
+	// 原型及请求作用域的绑定在解析时创建实例
+	if model.scope != ScopeSingleton && model.target == nil {
+		return nil
+	}
+
+	if model.target == nil && model.provider.IsValid() {
+		if err := construct(model); err != nil {
+			return err
+		}
+	}
+
+	model.injecting = true
+	defer func() {
+		model.injecting = false
+	}()
+
+	// 构造函数参数依赖先完成注入
+	for _, dep := range model.params {
+		if err := inject(dep); err != nil {
+			return err
+		}
 	}
 
 	var tval reflect.Value
 	var ttype reflect.Type
 	if model.target != nil {
-		tval = reflect.ValueOf(model.target).Elem()
-		ttype = reflect.TypeOf(model.target).Elem()
+		// 构造函数返回非结构体指针时无需字段注入
+		tv := reflect.ValueOf(model.target)
+		if tv.Kind() != reflect.Ptr || tv.Elem().Kind() != reflect.Struct {
+			markInjected(model)
+			return nil
+		}
+		tval = tv.Elem()
+		ttype = tv.Elem().Type()
 	} else if !model.reflectTarget.IsZero() {
 		tval = model.reflectTarget.Elem()
 		ttype = model.reflectTarget.Elem().Type()
 	} else {
-		name := model.metaType.Name()
-		if model.metaType.Kind() == reflect.Ptr {
-			name = "*" + model.metaType.Elem().Name()
-		}
-		panic(fmt.Sprintf("can not resolve the type %s", name))
+		return fmt.Errorf("can not resolve the type %s", typeName(model.metaType))
 	}
 
-	// tval := reflect.ValueOf(model.target).Elem()
-	// ttype := reflect.TypeOf(model.target).Elem()
 	nfield := ttype.NumField()
-	if nfield <= 0 {
-		model.injected = true
-		return
-	}
-
-	model.injecting = true
 	for i := 0; i < nfield; i++ {
 		field := ttype.Field(i)
+		if tag, ok := field.Tag.Lookup("value"); ok {
+			if err := injectValue(tval.Field(i), tag); err != nil {
+				return fmt.Errorf("%s.%s: %s", typeName(ttype), field.Name, err.Error())
+			}
+			continue
+		}
+
 		val, ok := field.Tag.Lookup("wired")
 		if !ok {
 			continue
 		}
 
-		if strings.ToLower(strings.TrimSpace(val)) != "autowired" {
+		tag, err := parseWiredTag(val)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %s", typeName(ttype), field.Name, err.Error())
+		}
+		if tag == nil {
 			continue
 		}
 
 		ftyp := field.Type
-		if ftyp.Kind() != reflect.Struct && ftyp.Kind() != reflect.Interface {
-			if ftyp.Kind() != reflect.Ptr || ftyp.Elem().Kind() != reflect.Struct {
-				name := ftyp.Name()
-				if ftyp.Kind() == reflect.Ptr {
-					name = "*" + ftyp.Elem().Name()
-				}
-				panic(fmt.Sprintf("can not resolve the type %s", name))
+		if ftyp == advisorType {
+			setField(tval.Field(i), reflect.ValueOf(&Advisor{container: model.owner, itype: model.metaType}))
+			continue
+		}
+
+		if isScopedAccessor(ftyp) {
+			setField(tval.Field(i), scopedProvider(model, ftyp, tag))
+			continue
+		}
+
+		if tag.lazy {
+			if !isLazy(ftyp) {
+				return fmt.Errorf("%s.%s: lazy injection requires a field of type func() T", typeName(ttype), field.Name)
 			}
+			setField(tval.Field(i), lazyProvider(model, ftyp, tag))
+			continue
 		}
 
-		scope := false
-		var children []*wiredModel
-
-		if field.Anonymous {
-			if ftyp.Kind() == reflect.Struct {
-				scope = true
-				children = []*wiredModel{&wiredModel{
-					metaType:      ftyp,
-					priority:      0,
-					proxy:         false,
-					reflectTarget: tval.Field(i).Addr(),
-				}}
-			} else if ftyp.Kind() == reflect.Ptr && !tval.Field(i).IsNil() {
-				scope = true
-				children = []*wiredModel{&wiredModel{
-					metaType:      ftyp,
-					priority:      0,
-					proxy:         false,
-					reflectTarget: tval.Field(i),
-				}}
+		if isCollection(ftyp) {
+			if tag.name != "" {
+				return fmt.Errorf("%s.%s: name is not supported by slice or map injection", typeName(ttype), field.Name)
 			}
+
+			collection, err := injectCollection(model, ftyp)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %s", typeName(ttype), field.Name, err.Error())
+			}
+			setField(tval.Field(i), collection)
+			continue
 		}
 
-		if !scope {
-			children = resovle(ftyp)
+		if !injectable(ftyp) {
+			return fmt.Errorf("%s.%s: can not resolve the type %s", typeName(ttype), field.Name, typeName(ftyp))
 		}
 
-		index := -1
-		// ftyp := field.Type
-		// children := resovle(ftyp)
-		for ich, child := range children {
-			if child.injecting {
-				continue
+		// 匿名嵌入的结构体, 注入其字段
+		if nested := embedded(model, tval.Field(i), field); nested != nil {
+			if err := inject(nested); err != nil {
+				return err
 			}
+			continue
+		}
 
-			inject(child)
-
-			if model.metaType == ftyp {
-				if !child.proxy {
-					// 不支持多层代理
-					index = ich
-					break
-				}
-			} else {
-				index = ich
-				break
+		children, err := fieldCandidates(model, ftyp, tag)
+		if err != nil {
+			if tag.optional {
+				continue
 			}
+			return fmt.Errorf("%s.%s: %s", typeName(ttype), field.Name, err.Error())
 		}
 
-		if !scope {
-			if index < 0 {
-				name := ftyp.Name()
-				if ftyp.Kind() == reflect.Ptr {
-					name = "*" + ftyp.Elem().Name()
-				}
-				panic(fmt.Sprintf("can not resolve the type %s", name))
-			}
+		// 循环依赖中的实例已创建, 直接注入, 其字段在返回后继续注入
+		ins, err := obtain(children[0], model.ctx)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %s", typeName(ttype), field.Name, err.Error())
+		}
+
+		setField(tval.Field(i), reflect.ValueOf(ins))
+	}
+
+	markInjected(model)
+	return nil
+}
+
+// 设置字段值, 支持未导出字段
+func setField(fieldVal reflect.Value, val reflect.Value) {
+	ptr := reflect.NewAt(fieldVal.Type(), unsafe.Pointer(fieldVal.UnsafeAddr())).Elem()
+	ptr.Set(val)
+}
+
+// 是否为可注入的字段类型
+func injectable(ftyp reflect.Type) bool {
+	switch ftyp.Kind() {
+	case reflect.Struct, reflect.Interface:
+		return true
+	case reflect.Ptr:
+		return ftyp.Elem().Kind() == reflect.Struct
+	default:
+		return false
+	}
+}
+
+// 匿名嵌入的结构体或非nil结构体指针, 作为独立的模型注入其字段
+// 非匿名字段或nil指针返回nil, 从容器中解析
+func embedded(model *wiredModel, fieldVal reflect.Value, field reflect.StructField) *wiredModel {
+	if !field.Anonymous {
+		return nil
+	}
+
+	nested := &wiredModel{metaType: field.Type, owner: model.owner, ctx: model.ctx}
+	switch {
+	case field.Type.Kind() == reflect.Struct && fieldVal.CanAddr():
+		nested.reflectTarget = fieldVal.Addr()
+	case field.Type.Kind() == reflect.Ptr && fieldVal.IsValid() && !fieldVal.IsNil():
+		nested.reflectTarget = fieldVal
+	default:
+		return nil
+	}
+	return nested
+}
+
+// 字段可注入的绑定, 按注入顺序排列
+func fieldCandidates(model *wiredModel, ftyp reflect.Type, tag *wiredTag) ([]*wiredModel, error) {
+	children := model.owner.lookup(ftyp)
+	if len(children) <= 0 {
+		return nil, fmt.Errorf("can not resolve the type %s", typeName(ftyp))
+	}
 
-			fieldVal := tval.Field(i)
-			ptr := reflect.NewAt(ftyp, unsafe.Pointer(fieldVal.UnsafeAddr())).Elem()
-			ptr.Set(reflect.ValueOf(children[index].target))
+	if tag.name != "" {
+		children = filterNamed(children, tag.name)
+		if len(children) <= 0 {
+			return nil, fmt.Errorf("no binding of type %s named %s", typeName(ftyp), tag.name)
 		}
 	}
 
-	model.injecting = false
+	// 代理注入自身类型时, 注入代理链中的下一层
+	if model.proxy && model.metaType == ftyp && tag.name == "" {
+		children = delegates(children, model)
+	}
+
+	var candidates []*wiredModel
+	for _, child := range children {
+		if child == model || child == model.origin {
+			continue
+		}
+
+		// 非代理实例注入自身类型时跳过代理
+		if model.metaType == ftyp && !model.proxy && child.proxy {
+			continue
+		}
+		candidates = append(candidates, child)
+	}
+
+	if len(candidates) <= 0 {
+		return nil, fmt.Errorf("can not resolve the type %s", typeName(ftyp))
+	}
+	return candidates, nil
+}
+
+// 标记注入完成, 并记录依赖顺序
+func markInjected(model *wiredModel) {
 	model.injected = true
+	if model.target != nil && model.scope == ScopeSingleton {
+		model.owner.injectOrder = append(model.owner.injectOrder, model)
+	}
 }
 
-// 获取对象
-func resovle(itype reflect.Type) []*wiredModel {
-	if len(container) <= 0 {
-		name := itype.Name()
-		if itype.Kind() == reflect.Ptr {
-			name = "*" + itype.Elem().Name()
+// 调用构造函数创建单例, 参数从容器中解析并完成注入
+func construct(model *wiredModel) error {
+	if model.constructing {
+		return fmt.Errorf("circular dependency when constructing %s", typeName(model.metaType))
+	}
+
+	model.constructing = true
+	defer func() {
+		model.constructing = false
+	}()
+
+	target, params, err := call(model, nil)
+	if err != nil {
+		return err
+	}
+
+	model.target = target
+	model.params = params
+	return nil
+}
+
+// 调用构造函数, 返回创建的实例及参数依赖
+// 单例参数完成注入后传入, 原型及请求作用域的参数按ctx创建
+func call(model *wiredModel, ctx context.Context) (interface{}, []*wiredModel, error) {
+	ctype := model.provider.Type()
+	args := make([]reflect.Value, ctype.NumIn())
+	params := make([]*wiredModel, ctype.NumIn())
+	for i := 0; i < ctype.NumIn(); i++ {
+		ptype := ctype.In(i)
+		candidates := model.owner.lookup(ptype)
+		if len(candidates) <= 0 {
+			return nil, nil, fmt.Errorf("constructor of %s: can not resolve parameter %d type %s", typeName(model.metaType), i, typeName(ptype))
+		}
+
+		dep := candidates[0]
+		if dep.scope != ScopeSingleton {
+			arg, err := obtain(dep, ctx)
+			if err != nil {
+				return nil, nil, fmt.Errorf("constructor of %s: %s", typeName(model.metaType), err.Error())
+			}
+			args[i] = reflect.ValueOf(arg)
+			params[i] = dep
+			continue
 		}
-		panic(fmt.Sprintf("can not resolve the type %s", name))
+
+		// 单例参数先完成注入, 构造函数可校验参数的依赖
+		// 字段循环依赖中正在注入的参数直接传入
+		if err := inject(dep); err != nil {
+			return nil, nil, err
+		}
+		if dep.target == nil {
+			return nil, nil, fmt.Errorf("constructor of %s: the type %s is not constructed", typeName(model.metaType), typeName(dep.metaType))
+		}
+		args[i] = reflect.ValueOf(dep.target)
+		params[i] = dep
 	}
 
-	targets := container[itype]
-	if len(targets) <= 0 {
-		name := itype.Name()
-		if itype.Kind() == reflect.Ptr {
-			name = "*" + itype.Elem().Name()
+	results := model.provider.Call(args)
+	if len(results) == 2 && !results[1].IsNil() {
+		return nil, nil, fmt.Errorf("constructor of %s: %s", typeName(model.metaType), results[1].Interface().(error).Error())
+	}
+	if isNilValue(results[0]) {
+		return nil, nil, fmt.Errorf("constructor of %s returned nil", typeName(model.metaType))
+	}
+
+	return results[0].Interface(), params, nil
+}
+
+// 获取实例, 构造函数注册的实例须在Inject后获取
+// 原型作用域每次创建新实例, 请求作用域须通过ResovleScoped获取
+func instance(model *wiredModel) interface{} {
+	if model.scope != ScopeSingleton {
+		ins, err := obtain(model, nil)
+		if err != nil {
+			panic(err.Error())
 		}
-		panic(fmt.Sprintf("can not resolve the type %s", name))
+		return ins
+	}
+
+	if model.target == nil {
+		panic(fmt.Sprintf("the type %s is not constructed, call Inject first", typeName(model.metaType)))
+	}
+	return model.target
+}
+
+// 是否为nil
+func isNilValue(val reflect.Value) bool {
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return val.IsNil()
+	default:
+		return false
+	}
+}
+
+// 获取对象
+func (c *Container) resovle(itype reflect.Type) []*wiredModel {
+	targets := c.lookup(itype)
+	if len(targets) <= 0 {
+		panic(fmt.Sprintf("can not resolve the type %s", typeName(itype)))
 	}
 
 	return targets
 }
+
+// 代理链中位于代理之后的绑定
+func delegates(targets []*wiredModel, proxy *wiredModel) []*wiredModel {
+	for i, t := range targets {
+		if t == proxy {
+			return targets[i+1:]
+		}
+	}
+	return targets
+}
+
+// 校验代理链, 同一类型的代理优先级相同时无法确定代理顺序
+func (c *Container) checkProxyChains() error {
+	for itype, targets := range c.bindings {
+		for i := 1; i < len(targets); i++ {
+			prev, cur := targets[i-1], targets[i]
+			if prev.proxy && cur.proxy && prev.priority == cur.priority {
+				return fmt.Errorf("ambiguous proxy chain of type %s: proxies %T and %T have the same priority %d",
+					typeName(itype), prev.target, cur.target, cur.priority)
+			}
+		}
+	}
+	return nil
+}
+
+// 筛选指定名称的绑定
+func filterNamed(targets []*wiredModel, name string) []*wiredModel {
+	var named []*wiredModel
+	for _, t := range targets {
+		if t.name == name {
+			named = append(named, t)
+		}
+	}
+	return named
+}
+
+// 类型名称
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		return "*" + t.Elem().Name()
+	}
+	return t.Name()
+}
@@ -0,0 +1,82 @@
+package wireutils
+
+import (
+	"context"
+	"reflect"
+)
+
+// 默认容器, 包级函数均使用该容器
+var defaultContainer = New()
+
+// 获取默认容器
+func Default() *Container {
+	return defaultContainer
+}
+
+// 映射接口实例
+// opts 绑定选项, 如 Named("primary") 指定绑定名称
+// 泛型版本见 BindAs[T]
+func Bind(itype reflect.Type, target interface{}, proxy bool, priority int, opts ...BindOption) {
+	defaultContainer.Bind(itype, target, proxy, priority, opts...)
+}
+
+// 注册构造函数
+// constructor 构造函数, 返回值为 T 或 (T, error), 以T类型绑定
+// 参数从容器中解析, 在Inject时按依赖顺序调用, 构造失败时Inject返回错误
+//
+//	wireutils.Provide(func(repo Repo, cfg *Config) (Service, error) {
+//		return newService(repo, cfg)
+//	}, 1)
+func Provide(constructor interface{}, priority int, opts ...BindOption) {
+	defaultContainer.Provide(constructor, priority, opts...)
+}
+
+// 使用impl替换itype的所有绑定, 通常用于在测试中注入mock
+func Override(itype reflect.Type, impl interface{}) {
+	defaultContainer.Override(itype, impl)
+}
+
+// 重置默认容器的注入状态并恢复Override替换的绑定
+func Reset() {
+	defaultContainer.Reset()
+}
+
+// 为itype类型注册方法拦截器, 按注册顺序由外到内执行
+func Intercept(itype reflect.Type, interceptors ...Interceptor) {
+	defaultContainer.Intercept(itype, interceptors...)
+}
+
+// 获取对象
+func Resovle(itype reflect.Type) interface{} {
+	return defaultContainer.Resovle(itype)
+}
+
+// 获取指定名称的对象
+func ResovleNamed(itype reflect.Type, name string) interface{} {
+	return defaultContainer.ResovleNamed(itype, name)
+}
+
+// 获取所有对象
+func ResovleAll(itype reflect.Type) []interface{} {
+	return defaultContainer.ResovleAll(itype)
+}
+
+// 按请求上下文获取对象, 请求作用域的实例在同一请求中只创建一次
+func ResovleScoped(ctx context.Context, itype reflect.Type) (interface{}, error) {
+	return defaultContainer.ResovleScoped(ctx, itype)
+}
+
+// 注入默认容器中的对象
+func Inject() error {
+	return defaultContainer.Inject()
+}
+
+// 按初始化的相反顺序调用默认容器中实例的PreDestroy
+func Shutdown() error {
+	return defaultContainer.Shutdown()
+}
+
+// 导出默认容器的依赖图, 用于调试
+func Graph() *DependencyGraph {
+	return defaultContainer.Graph()
+}
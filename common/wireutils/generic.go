@@ -0,0 +1,100 @@
+package wireutils
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// 以类型参数T映射接口实例
+// T 通常为接口类型, target 须为实现了T的结构体指针
+//
+//	wireutils.BindAs[srv_isrv.TestSrvInterface](&testSrvImpl{}, false, 1)
+func BindAs[T any](target T, proxy bool, priority int, opts ...BindOption) {
+	Bind(typeOf[T](), target, proxy, priority, opts...)
+}
+
+// 为T类型注册方法拦截器, 按注册顺序由外到内执行
+//
+//	wireutils.InterceptType[srv_isrv.TestSrvInterface](wireutils.Around(func(inv *wireutils.Invocation) {
+//		start := time.Now()
+//		inv.Proceed()
+//		loggers.GetLogger().Info(fmt.Sprintf("%s cost %s", inv.Method, time.Since(start)))
+//	}))
+func InterceptType[T any](interceptors ...Interceptor) {
+	Intercept(typeOf[T](), interceptors...)
+}
+
+// 获取T类型的对象, 无法解析时panic
+func Resolve[T any]() T {
+	return Resovle(typeOf[T]()).(T)
+}
+
+// 获取T类型指定名称的对象, 无法解析时panic
+func ResolveNamed[T any](name string) T {
+	return ResovleNamed(typeOf[T](), name).(T)
+}
+
+// 获取T类型的所有对象, 未绑定时返回空切片
+func ResolveAll[T any]() []T {
+	defaultContainer.mu.Lock()
+	targets := append([]*wiredModel{}, defaultContainer.lookup(typeOf[T]())...)
+	defaultContainer.mu.Unlock()
+
+	all := make([]T, 0, len(targets))
+	for _, t := range targets {
+		all = append(all, instance(t).(T))
+	}
+	return all
+}
+
+// 获取T类型的对象, 无法解析时返回错误
+func TryResolve[T any]() (T, error) {
+	var zero T
+	itype := typeOf[T]()
+
+	defaultContainer.mu.Lock()
+	targets := defaultContainer.lookup(itype)
+	defaultContainer.mu.Unlock()
+
+	if len(targets) <= 0 {
+		return zero, fmt.Errorf("can not resolve the type %s", typeName(itype))
+	}
+	target := targets[0].target
+	if targets[0].scope != ScopeSingleton {
+		ins, err := obtain(targets[0], nil)
+		if err != nil {
+			return zero, err
+		}
+		target = ins
+	}
+	if target == nil {
+		return zero, fmt.Errorf("the type %s is not constructed, call Inject first", typeName(itype))
+	}
+
+	ins, ok := target.(T)
+	if !ok {
+		return zero, fmt.Errorf("the instance %T does not implement %s", target, typeName(itype))
+	}
+	return ins, nil
+}
+
+// 按请求上下文获取T类型的对象, 请求作用域的实例在同一请求中只创建一次
+func ResolveScoped[T any](ctx context.Context) (T, error) {
+	var zero T
+	ins, err := ResovleScoped(ctx, typeOf[T]())
+	if err != nil {
+		return zero, err
+	}
+
+	typed, ok := ins.(T)
+	if !ok {
+		return zero, fmt.Errorf("the instance %T does not implement %s", ins, typeName(typeOf[T]()))
+	}
+	return typed, nil
+}
+
+// 类型参数对应的反射类型
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
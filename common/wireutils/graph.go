@@ -0,0 +1,384 @@
+package wireutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 依赖图节点, 对应一个绑定
+type GraphNode struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"` // 绑定的类型
+	Bean     string `json:"bean"` // 实例类型或构造函数
+	Name     string `json:"name,omitempty"`
+	Proxy    bool   `json:"proxy"`
+	Priority int    `json:"priority"`
+	Scope    string `json:"scope"`
+}
+
+// 依赖图的边, From 依赖 To
+type GraphEdge struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Field       string `json:"field"`       // 注入的字段, 构造函数参数为 arg0, arg1...
+	Constructor bool   `json:"constructor"` // 是否为构造函数参数
+	Lazy        bool   `json:"lazy"`        // 是否为延迟注入
+}
+
+// 依赖图
+type DependencyGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// 依赖错误, 包含所有无法解析的依赖及循环依赖
+type DependencyError struct {
+	Problems []string
+}
+
+func (err *DependencyError) Error() string {
+	return "dependency check failed:\n  - " + strings.Join(err.Problems, "\n  - ")
+}
+
+// 导出当前容器的依赖图, 用于调试
+// 返回值为接口的构造函数在构造前无法确定实例类型, 仅包含构造函数参数依赖
+func (c *Container) Graph() *DependencyGraph {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buildGraph().export()
+}
+
+// 导出为JSON
+func (g *DependencyGraph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// 导出为graphviz的DOT格式, 构造函数参数依赖以粗线表示, 延迟注入以点线表示
+func (g *DependencyGraph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph wireutils {\n")
+	for _, node := range g.Nodes {
+		label := node.Type + "\n" + node.Bean
+		if node.Name != "" {
+			label += "\nname=" + node.Name
+		}
+		if node.Scope != ScopeSingleton.String() {
+			label += "\nscope=" + node.Scope
+		}
+		attrs := "label=" + strconv.Quote(label)
+		if node.Proxy {
+			attrs += ", style=dashed"
+		}
+		sb.WriteString(fmt.Sprintf("  %s [%s];\n", strconv.Quote(node.ID), attrs))
+	}
+	for _, edge := range g.Edges {
+		attrs := "label=" + strconv.Quote(edge.Field)
+		if edge.Constructor {
+			attrs += ", style=bold"
+		} else if edge.Lazy {
+			attrs += ", style=dotted"
+		}
+		sb.WriteString(fmt.Sprintf("  %s -> %s [%s];\n", strconv.Quote(edge.From), strconv.Quote(edge.To), attrs))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// 依赖关系
+type dependency struct {
+	to          *wiredModel
+	field       string
+	constructor bool
+	lazy        bool
+}
+
+// 注入前构建的依赖图
+type dependencyGraph struct {
+	nodes    []*wiredModel
+	deps     map[*wiredModel][]dependency
+	problems []string
+}
+
+// 分析容器中所有绑定的依赖, 记录无法解析的依赖及构造函数循环依赖
+func (c *Container) buildGraph() *dependencyGraph {
+	g := &dependencyGraph{deps: make(map[*wiredModel][]dependency)}
+
+	itypes := make([]reflect.Type, 0, len(c.bindings))
+	for itype := range c.bindings {
+		itypes = append(itypes, itype)
+	}
+	sort.Slice(itypes, func(i, j int) bool {
+		return itypes[i].String() < itypes[j].String()
+	})
+
+	for _, itype := range itypes {
+		for _, model := range c.bindings[itype] {
+			g.nodes = append(g.nodes, model)
+			g.analyze(model)
+		}
+	}
+
+	g.findCycles()
+	return g
+}
+
+func (g *dependencyGraph) addf(format string, args ...interface{}) {
+	g.problems = append(g.problems, fmt.Sprintf(format, args...))
+}
+
+func (g *dependencyGraph) err() error {
+	if len(g.problems) == 0 {
+		return nil
+	}
+	return &DependencyError{Problems: g.problems}
+}
+
+// 分析绑定的构造函数参数及注入字段
+func (g *dependencyGraph) analyze(model *wiredModel) {
+	if model.params != nil {
+		// 已构造的实例使用构造时解析的参数
+		for i, dep := range model.params {
+			g.deps[model] = append(g.deps[model], dependency{to: dep, field: fmt.Sprintf("arg%d", i), constructor: true})
+		}
+	} else if model.provider.IsValid() {
+		ctype := model.provider.Type()
+		for i := 0; i < ctype.NumIn(); i++ {
+			ptype := ctype.In(i)
+			candidates := model.owner.lookup(ptype)
+			if len(candidates) <= 0 {
+				g.addf("%s parameter %d: can not resolve the type %s", beanName(model), i, typeName(ptype))
+				continue
+			}
+			g.checkScope(model, candidates[0], fmt.Sprintf("arg%d", i))
+			g.deps[model] = append(g.deps[model], dependency{to: candidates[0], field: fmt.Sprintf("arg%d", i), constructor: true})
+		}
+	}
+
+	var tval reflect.Value
+	var ttype reflect.Type
+	if model.target != nil {
+		tval = reflect.ValueOf(model.target)
+		ttype = tval.Type()
+	} else if model.provider.IsValid() {
+		ttype = model.provider.Type().Out(0)
+	}
+	if ttype == nil || ttype.Kind() != reflect.Ptr || ttype.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	if tval.IsValid() {
+		tval = tval.Elem()
+	}
+	g.analyzeFields(model, model, ttype.Elem(), tval, "")
+}
+
+// 分析结构体的注入字段, 匿名嵌入的结构体字段记录在所属绑定上
+// tval 为空时仅分析类型
+func (g *dependencyGraph) analyzeFields(owner *wiredModel, model *wiredModel, ttype reflect.Type, tval reflect.Value, prefix string) {
+	for i := 0; i < ttype.NumField(); i++ {
+		field := ttype.Field(i)
+		path := prefix + field.Name
+		if tag, ok := field.Tag.Lookup("value"); ok {
+			if !valueResolvable(field.Type, tag) {
+				key, _ := parseValueTag(tag)
+				g.addf("%s.%s: config %s is not found", beanName(owner), path, key)
+			}
+			continue
+		}
+
+		val, ok := field.Tag.Lookup("wired")
+		if !ok {
+			continue
+		}
+
+		tag, err := parseWiredTag(val)
+		if err != nil {
+			g.addf("%s.%s: %s", beanName(owner), path, err.Error())
+			continue
+		}
+		if tag == nil {
+			continue
+		}
+
+		ftyp := field.Type
+		if ftyp == advisorType {
+			continue
+		}
+
+		if isScopedAccessor(ftyp) {
+			// 按请求上下文在调用时解析, 无法解析时不视为错误
+			if children, err := fieldCandidates(model, ftyp.Out(0), tag); err == nil {
+				g.deps[owner] = append(g.deps[owner], dependency{to: children[0], field: path, lazy: true})
+			}
+			continue
+		}
+
+		if tag.lazy {
+			if !isLazy(ftyp) {
+				g.addf("%s.%s: lazy injection requires a field of type func() T", beanName(owner), path)
+				continue
+			}
+			// 延迟注入在首次调用时解析, 无法解析时不视为错误
+			if children, err := fieldCandidates(model, ftyp.Out(0), tag); err == nil {
+				g.deps[owner] = append(g.deps[owner], dependency{to: children[0], field: path, lazy: true})
+			}
+			continue
+		}
+
+		if isCollection(ftyp) {
+			if tag.name != "" {
+				g.addf("%s.%s: name is not supported by slice or map injection", beanName(owner), path)
+				continue
+			}
+			candidates, keys := collectionCandidates(model, ftyp.Elem())
+			for idx, child := range candidates {
+				elem := fmt.Sprintf("%s[%d]", path, idx)
+				if ftyp.Kind() == reflect.Map {
+					elem = fmt.Sprintf("%s[%s]", path, keys[idx])
+				}
+				g.checkScope(owner, child, elem)
+				g.deps[owner] = append(g.deps[owner], dependency{to: child, field: elem})
+			}
+			continue
+		}
+
+		if !injectable(ftyp) {
+			g.addf("%s.%s: can not resolve the type %s", beanName(owner), path, typeName(ftyp))
+			continue
+		}
+
+		var fieldVal reflect.Value
+		if tval.IsValid() {
+			fieldVal = tval.Field(i)
+		}
+		if field.Anonymous {
+			if ftyp.Kind() == reflect.Struct {
+				g.analyzeFields(owner, &wiredModel{metaType: ftyp, owner: owner.owner}, ftyp, fieldVal, path+".")
+				continue
+			}
+			if ftyp.Kind() == reflect.Ptr && fieldVal.IsValid() && !fieldVal.IsNil() {
+				g.analyzeFields(owner, &wiredModel{metaType: ftyp, owner: owner.owner}, ftyp.Elem(), fieldVal.Elem(), path+".")
+				continue
+			}
+		}
+
+		children, err := fieldCandidates(model, ftyp, tag)
+		if err != nil {
+			if tag.optional {
+				continue
+			}
+			g.addf("%s.%s: %s", beanName(owner), path, err.Error())
+			continue
+		}
+		g.checkScope(owner, children[0], path)
+		g.deps[owner] = append(g.deps[owner], dependency{to: children[0], field: path})
+	}
+}
+
+// 检查依赖的作用域, 请求作用域的实例只能直接注入请求作用域的实例
+// 其他实例须通过 func(context.Context) T 类型的字段按请求获取
+func (g *dependencyGraph) checkScope(owner *wiredModel, dep *wiredModel, path string) {
+	if dep.scope == ScopeRequest && owner.scope != ScopeRequest {
+		g.addf("%s.%s: request scoped %s must be injected as func(context.Context) %s",
+			beanName(owner), path, typeName(dep.metaType), typeName(dep.metaType))
+	}
+}
+
+// 查找构造函数参数间及原型实例间的循环依赖
+// 单例字段注入的循环依赖可以完成注入, 构造函数参数及原型依赖的循环无法创建实例
+func (g *dependencyGraph) findCycles() {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[*wiredModel]int)
+	var stack []*wiredModel
+	var visit func(model *wiredModel)
+	visit = func(model *wiredModel) {
+		state[model] = visiting
+		stack = append(stack, model)
+		for _, dep := range g.deps[model] {
+			if dep.lazy || (!dep.constructor && dep.to.scope != ScopePrototype) {
+				continue
+			}
+
+			switch state[dep.to] {
+			case unvisited:
+				visit(dep.to)
+			case visiting:
+				var names []string
+				for i := len(stack) - 1; i >= 0; i-- {
+					names = append([]string{beanName(stack[i])}, names...)
+					if stack[i] == dep.to {
+						break
+					}
+				}
+				names = append(names, beanName(dep.to))
+				g.addf("circular dependency: %s", strings.Join(names, " -> "))
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[model] = visited
+	}
+
+	for _, model := range g.nodes {
+		if state[model] == unvisited {
+			visit(model)
+		}
+	}
+}
+
+// 转换为导出的依赖图
+func (g *dependencyGraph) export() *DependencyGraph {
+	graph := &DependencyGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	for _, model := range g.nodes {
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			ID:       nodeID(model),
+			Type:     model.metaType.String(),
+			Bean:     beanName(model),
+			Name:     model.name,
+			Proxy:    model.proxy,
+			Priority: model.priority,
+			Scope:    model.scope.String(),
+		})
+		for _, dep := range g.deps[model] {
+			graph.Edges = append(graph.Edges, GraphEdge{
+				From:        nodeID(model),
+				To:          nodeID(dep.to),
+				Field:       dep.field,
+				Constructor: dep.constructor,
+				Lazy:        dep.lazy,
+			})
+		}
+	}
+	return graph
+}
+
+// 节点标识 绑定类型/实例类型[#名称]
+func nodeID(model *wiredModel) string {
+	id := model.metaType.String() + "/" + beanName(model)
+	if model.name != "" {
+		id += "#" + model.name
+	}
+	return id
+}
+
+// 实例名称, 构造函数未调用时为构造函数名称
+func beanName(model *wiredModel) string {
+	if model.target != nil {
+		return fmt.Sprintf("%T", model.target)
+	}
+	if model.provider.IsValid() {
+		if fn := runtime.FuncForPC(model.provider.Pointer()); fn != nil {
+			return fn.Name()
+		}
+	}
+	return model.metaType.String()
+}
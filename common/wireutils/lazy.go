@@ -0,0 +1,41 @@
+package wireutils
+
+import (
+	"reflect"
+	"sync"
+)
+
+// 是否为延迟注入的字段类型 func() T, T为接口或结构体指针
+func isLazy(ftyp reflect.Type) bool {
+	return ftyp.Kind() == reflect.Func && ftyp.NumIn() == 0 && ftyp.NumOut() == 1 && collectable(ftyp.Out(0))
+}
+
+// 延迟注入的函数, 首次调用时解析实例并缓存
+// 无法解析时panic, 可选注入时返回nil且下次调用重新解析
+func lazyProvider(model *wiredModel, ftyp reflect.Type, tag *wiredTag) reflect.Value {
+	etyp := ftyp.Out(0)
+	lock := &sync.Mutex{}
+	var resolved reflect.Value
+
+	return reflect.MakeFunc(ftyp, func(args []reflect.Value) []reflect.Value {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if resolved.IsValid() {
+			return []reflect.Value{resolved}
+		}
+
+		children, err := fieldCandidates(model, etyp, tag)
+		if err != nil {
+			if tag.optional {
+				return []reflect.Value{reflect.Zero(etyp)}
+			}
+			panic(err.Error())
+		}
+
+		val := reflect.New(etyp).Elem()
+		val.Set(reflect.ValueOf(instance(children[0])))
+		resolved = val
+		return []reflect.Value{resolved}
+	})
+}
@@ -0,0 +1,75 @@
+package wireutils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 初始化接口
+// 实例实现该接口时, 在Inject完成后按依赖顺序调用, 被依赖的实例先初始化
+type Initializer interface {
+	PostConstruct() error
+}
+
+// 销毁接口
+// 实例实现该接口时, 在Shutdown时按初始化的相反顺序调用
+type Disposer interface {
+	PreDestroy() error
+}
+
+// 按依赖顺序调用实例的PostConstruct
+func (c *Container) postConstruct() error {
+	visited := make(map[interface{}]bool)
+	for _, model := range c.injectOrder {
+		if model.target == nil || visited[model.target] {
+			continue
+		}
+		visited[model.target] = true
+
+		if init, ok := model.target.(Initializer); ok {
+			if err := init.PostConstruct(); err != nil {
+				return fmt.Errorf("post construct %s: %s", typeName(model.metaType), err.Error())
+			}
+		}
+		c.initialized = append(c.initialized, model.target)
+	}
+
+	return nil
+}
+
+// 按初始化的相反顺序调用实例的PreDestroy
+// 返回所有销毁失败的错误
+func (c *Container) Shutdown() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.shutdown {
+		return nil
+	}
+	c.shutdown = true
+
+	var errs []error
+	for i := len(c.initialized) - 1; i >= 0; i-- {
+		if disposer, ok := c.initialized[i].(Disposer); ok {
+			if err := preDestroy(disposer); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// 调用PreDestroy, panic时转为错误
+func preDestroy(disposer Disposer) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("pre destroy %T: %v", disposer, r)
+		}
+	}()
+
+	if err := disposer.PreDestroy(); err != nil {
+		return fmt.Errorf("pre destroy %T: %s", disposer, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package wireutils
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// 配置来源, 用于 `value` 标签注入及 OnProfile, OnProperty 条件绑定
+type PropertySource interface {
+	// 按路径读取配置项, 如 redis.host
+	Lookup(key string) (string, bool)
+	// 将配置项解析到target指针指向的值, 配置项不存在时使用默认值def, 都不存在时返回错误
+	Value(key string, target interface{}, def ...string) error
+	// profile是否激活
+	IsProfileActive(profile string) bool
+}
+
+// 依赖注入使用的配置来源
+var propertySource atomic.Value
+
+// 设置配置来源, 须在Inject前设置
+// wireutils不依赖config, 由main设置
+//
+//	wireutils.SetPropertySource(config.Properties())
+func SetPropertySource(source PropertySource) {
+	if source != nil {
+		propertySource.Store(&source)
+	}
+}
+
+// 当前的配置来源, 未设置时没有任何配置项
+func properties() PropertySource {
+	if source, ok := propertySource.Load().(*PropertySource); ok {
+		return *source
+	}
+	return emptyProperties{}
+}
+
+// 未设置配置来源时使用
+type emptyProperties struct{}
+
+func (emptyProperties) Lookup(key string) (string, bool) {
+	return "", false
+}
+
+func (emptyProperties) Value(key string, target interface{}, def ...string) error {
+	return errors.New("property source is not set, call wireutils.SetPropertySource before Inject")
+}
+
+func (emptyProperties) IsProfileActive(profile string) bool {
+	return false
+}
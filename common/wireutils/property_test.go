@@ -0,0 +1,99 @@
+package wireutils
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// 基于map的配置来源
+type mapProperties map[string]string
+
+func (props mapProperties) Lookup(key string) (string, bool) {
+	val, ok := props[key]
+	return val, ok
+}
+
+func (props mapProperties) Value(key string, target interface{}, def ...string) error {
+	val, ok := props[key]
+	if !ok && len(def) > 0 {
+		val, ok = def[0], true
+	}
+	if !ok {
+		return fmt.Errorf("config %s is not found", key)
+	}
+
+	switch target := target.(type) {
+	case *string:
+		*target = val
+	case *int:
+		i, err := strconv.Atoi(val)
+		if err != nil {
+			return err
+		}
+		*target = i
+	default:
+		return fmt.Errorf("unsupported target %T", target)
+	}
+	return nil
+}
+
+func (props mapProperties) IsProfileActive(profile string) bool {
+	return props["profile"] == profile
+}
+
+type cache interface {
+	Name() string
+}
+
+type redisCache struct {
+	Host    string `value:"redis.host"`
+	Timeout int    `value:"redis.timeout:3000"`
+}
+
+func (cache *redisCache) Name() string {
+	return "redis"
+}
+
+type memoryCache struct{}
+
+func (cache *memoryCache) Name() string {
+	return "memory"
+}
+
+func TestPropertySource(t *testing.T) {
+	SetPropertySource(mapProperties{"profile": "test", "redis.host": "127.0.0.1"})
+
+	itype := reflect.TypeOf((*cache)(nil)).Elem()
+	c := New()
+	c.Bind(itype, &redisCache{}, false, 1, OnProperty("redis.host"), OnProfile("test"))
+	c.Bind(itype, &memoryCache{}, false, 9, OnMissingBinding[cache]())
+	if err := c.Inject(); err != nil {
+		t.Fatal(err)
+	}
+
+	redis, ok := c.Resovle(itype).(*redisCache)
+	if !ok {
+		t.Fatalf("resolved %T, want *redisCache", c.Resovle(itype))
+	}
+	if redis.Host != "127.0.0.1" || redis.Timeout != 3000 {
+		t.Errorf("injected host %s timeout %d, want 127.0.0.1 3000", redis.Host, redis.Timeout)
+	}
+}
+
+func TestPropertySourceCondition(t *testing.T) {
+	SetPropertySource(mapProperties{"profile": "prod"})
+
+	itype := reflect.TypeOf((*cache)(nil)).Elem()
+	c := New()
+	c.Bind(itype, &redisCache{}, false, 1, OnProperty("redis.host"))
+	c.Bind(itype, &memoryCache{}, false, 9, OnMissingBinding[cache]())
+	if err := c.Inject(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := c.Resovle(itype).(*memoryCache); !ok {
+		t.Fatalf("resolved %T, want *memoryCache", c.Resovle(itype))
+	}
+}
@@ -0,0 +1,36 @@
+package wireutils
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type provideDep struct{}
+
+type provideRepo struct {
+	D *provideDep `wired:"Autowired"`
+}
+
+type provideSvc struct {
+	repo *provideRepo
+}
+
+// 构造函数参数的字段在调用构造函数前完成注入
+func TestProvideArgumentInjected(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		c := New()
+		c.Bind(reflect.TypeOf(&provideDep{}), &provideDep{}, false, 1)
+		c.Bind(reflect.TypeOf(&provideRepo{}), &provideRepo{}, false, 1)
+		c.Provide(func(repo *provideRepo) (*provideSvc, error) {
+			if repo.D == nil {
+				return nil, errors.New("repo is not injected")
+			}
+			return &provideSvc{repo: repo}, nil
+		}, 1)
+
+		if err := c.Inject(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
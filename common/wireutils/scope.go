@@ -0,0 +1,201 @@
+package wireutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"looklapi/common/utils"
+	"reflect"
+	"sync"
+)
+
+// 实例作用域
+type Scope int8
+
+const (
+	ScopeSingleton Scope = iota // 单例, 默认作用域
+	ScopePrototype              // 原型, 每次解析时调用构造函数创建新实例
+	ScopeRequest                // 请求, 每个http请求创建一个实例, 存储于请求上下文
+)
+
+func (scope Scope) String() string {
+	switch scope {
+	case ScopePrototype:
+		return "prototype"
+	case ScopeRequest:
+		return "request"
+	default:
+		return "singleton"
+	}
+}
+
+// 指定实例作用域, 原型及请求作用域须通过Provide注册构造函数
+// 原型及请求作用域的实例在创建时完成注入并调用PostConstruct, 原型实例不调用PreDestroy
+//
+//	wireutils.Provide(newUnitOfWork, 1, wireutils.Scoped(wireutils.ScopeRequest))
+func Scoped(scope Scope) BindOption {
+	return func(model *wiredModel) {
+		model.scope = scope
+	}
+}
+
+// 请求上下文中存储请求作用域的键
+const requestScopeKey = "wireutils-request-scope"
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// 请求作用域, 存储一次http请求中创建的实例
+type requestScope struct {
+	mu        *sync.Mutex
+	instances map[*wiredModel]interface{}
+	order     []interface{} // 按创建顺序排列的实例
+}
+
+// 获取绑定的实例, 原型作用域每次创建新实例, 请求作用域从请求上下文获取
+func obtain(model *wiredModel, ctx context.Context) (interface{}, error) {
+	switch model.scope {
+	case ScopePrototype:
+		return create(model, ctx)
+	case ScopeRequest:
+		return requestInstance(model, ctx)
+	default:
+		if err := inject(model); err != nil {
+			return nil, err
+		}
+		if model.target == nil {
+			return nil, fmt.Errorf("the type %s is not constructed", typeName(model.metaType))
+		}
+		return model.target, nil
+	}
+}
+
+// 创建原型或请求作用域的实例, 完成字段注入并调用PostConstruct
+func create(model *wiredModel, ctx context.Context) (interface{}, error) {
+	target, _, err := call(model, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ins := &wiredModel{
+		metaType: model.metaType,
+		name:     model.name,
+		scope:    model.scope,
+		target:   target,
+		owner:    model.owner,
+		origin:   model,
+		ctx:      ctx,
+	}
+	if err := inject(ins); err != nil {
+		return nil, err
+	}
+
+	if init, ok := target.(Initializer); ok {
+		if err := init.PostConstruct(); err != nil {
+			return nil, fmt.Errorf("post construct %s: %s", typeName(model.metaType), err.Error())
+		}
+	}
+	return target, nil
+}
+
+// 获取请求作用域的实例, 同一请求中只创建一次
+func requestInstance(model *wiredModel, ctx context.Context) (interface{}, error) {
+	store := utils.GetHttpCtxStore(ctx)
+	if store == nil {
+		return nil, fmt.Errorf("request scoped %s must be resolved with a http request context", typeName(model.metaType))
+	}
+
+	// 请求作用域在请求开始时创建, 此处只读取, 请求上下文的存储不是并发安全的
+	rs, ok := store.Get(requestScopeKey).(*requestScope)
+	if !ok {
+		return nil, fmt.Errorf("request scoped %s: request scope is not started, use the RequestScopeHandler middleware", typeName(model.metaType))
+	}
+
+	rs.mu.Lock()
+	ins, ok := rs.instances[model]
+	rs.mu.Unlock()
+	if ok {
+		return ins, nil
+	}
+
+	// 创建期间不持有锁, 依赖的请求作用域实例在创建时获取
+	ins, err := create(model, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if exist, ok := rs.instances[model]; ok {
+		return exist, nil
+	}
+	rs.instances[model] = ins
+	rs.order = append(rs.order, ins)
+	return ins, nil
+}
+
+// 开始请求作用域, 由web中间件在处理请求前调用
+// 请求中的goroutine只读取请求作用域, 不再修改请求上下文的存储
+func BeginRequestScope(store utils.CtxStore) {
+	if store == nil {
+		return
+	}
+	store.Save(requestScopeKey, &requestScope{mu: &sync.Mutex{}, instances: make(map[*wiredModel]interface{})}, true)
+}
+
+// 结束请求作用域, 按创建的相反顺序调用请求作用域实例的PreDestroy
+// 由web中间件在请求结束时调用
+func EndRequestScope(store utils.CtxStore) error {
+	if store == nil {
+		return nil
+	}
+
+	rs, ok := store.Get(requestScopeKey).(*requestScope)
+	if !ok {
+		return nil
+	}
+	store.Remove(requestScopeKey)
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	var errs []error
+	for i := len(rs.order) - 1; i >= 0; i-- {
+		if disposer, ok := rs.order[i].(Disposer); ok {
+			if err := preDestroy(disposer); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 是否为请求作用域的注入字段 func(context.Context) T, T为接口或结构体指针
+func isScopedAccessor(ftyp reflect.Type) bool {
+	return ftyp.Kind() == reflect.Func && ftyp.NumIn() == 1 && ftyp.In(0) == contextType &&
+		ftyp.NumOut() == 1 && collectable(ftyp.Out(0))
+}
+
+// 按请求上下文获取实例的函数, 用于在单例中注入请求作用域的实例
+// 无法解析时panic, 可选注入时返回nil
+func scopedProvider(model *wiredModel, ftyp reflect.Type, tag *wiredTag) reflect.Value {
+	etyp := ftyp.Out(0)
+	return reflect.MakeFunc(ftyp, func(args []reflect.Value) []reflect.Value {
+		children, err := fieldCandidates(model, etyp, tag)
+		if err != nil {
+			if tag.optional {
+				return []reflect.Value{reflect.Zero(etyp)}
+			}
+			panic(err.Error())
+		}
+
+		ctx, _ := args[0].Interface().(context.Context)
+		ins, err := obtain(children[0], ctx)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		val := reflect.New(etyp).Elem()
+		val.Set(reflect.ValueOf(ins))
+		return []reflect.Value{val}
+	})
+}
@@ -0,0 +1,100 @@
+package wireutils
+
+import (
+	"context"
+	"looklapi/common/utils"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// 请求上下文存储, 非并发安全, 用于检查请求中只读取存储
+type mapStore map[string]interface{}
+
+func (store mapStore) Exists(key string) bool {
+	_, ok := store[key]
+	return ok
+}
+
+func (store mapStore) Get(key string) interface{} {
+	return store[key]
+}
+
+func (store mapStore) Save(key string, value interface{}, immutable bool) bool {
+	store[key] = value
+	return true
+}
+
+func (store mapStore) Remove(key string) bool {
+	delete(store, key)
+	return true
+}
+
+type unitOfWork struct {
+	destroyed *atomic.Int32
+}
+
+func (uow *unitOfWork) PreDestroy() error {
+	uow.destroyed.Add(1)
+	return nil
+}
+
+func TestRequestScopeConcurrentResolve(t *testing.T) {
+	destroyed := &atomic.Int32{}
+	c := New()
+	c.Provide(func() *unitOfWork {
+		return &unitOfWork{destroyed: destroyed}
+	}, 1, Scoped(ScopeRequest))
+	if err := c.Inject(); err != nil {
+		t.Fatal(err)
+	}
+
+	store := mapStore{}
+	BeginRequestScope(store)
+	ctx := context.WithValue(context.Background(), utils.HttpContextStore, utils.CtxStore(store))
+
+	itype := reflect.TypeOf(&unitOfWork{})
+	instances := make([]interface{}, 20)
+	wg := &sync.WaitGroup{}
+	for i := range instances {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ins, err := c.ResovleScoped(ctx, itype)
+			if err != nil {
+				t.Error(err)
+			}
+			instances[i] = ins
+		}(i)
+	}
+	wg.Wait()
+
+	for _, ins := range instances {
+		if ins != instances[0] {
+			t.Fatal("request scoped instances in the same request should be the same")
+		}
+	}
+
+	if err := EndRequestScope(store); err != nil {
+		t.Fatal(err)
+	}
+	if destroyed.Load() != 1 {
+		t.Errorf("PreDestroy called %d times, want 1", destroyed.Load())
+	}
+}
+
+func TestRequestScopeNotStarted(t *testing.T) {
+	c := New()
+	c.Provide(func() *unitOfWork {
+		return &unitOfWork{destroyed: &atomic.Int32{}}
+	}, 1, Scoped(ScopeRequest))
+	if err := c.Inject(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), utils.HttpContextStore, utils.CtxStore(mapStore{}))
+	if _, err := c.ResovleScoped(ctx, reflect.TypeOf(&unitOfWork{})); err == nil {
+		t.Error("resolving without BeginRequestScope should return an error")
+	}
+}
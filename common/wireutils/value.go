@@ -0,0 +1,36 @@
+package wireutils
+
+import (
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// 解析配置注入标签
+// `value:"redis.timeout"` 注入配置项redis.timeout
+// `value:"biz.limit:100"` 配置项不存在时注入默认值100
+func parseValueTag(tag string) (string, []string) {
+	key, def, hasDef := strings.Cut(tag, ":")
+	key = strings.TrimSpace(key)
+	if !hasDef {
+		return key, nil
+	}
+	return key, []string{def}
+}
+
+// 注入配置值, 支持基础类型, time.Duration, 切片及结构体
+func injectValue(fieldVal reflect.Value, tag string) error {
+	key, def := parseValueTag(tag)
+	ptr := reflect.NewAt(fieldVal.Type(), unsafe.Pointer(fieldVal.UnsafeAddr()))
+	return properties().Value(key, ptr.Interface(), def...)
+}
+
+// 配置项是否可以注入, 不存在且没有默认值时不能注入
+// 配置来源中的默认值(如config的default标签)同样可以注入
+func valueResolvable(ftyp reflect.Type, tag string) bool {
+	key, def := parseValueTag(tag)
+	if len(def) > 0 || ftyp.Kind() == reflect.Struct {
+		return true
+	}
+	return properties().Value(key, reflect.New(ftyp).Interface()) == nil
+}
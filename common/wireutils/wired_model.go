@@ -1,16 +1,28 @@
 package wireutils
 
-import "reflect"
+import (
+	"context"
+	"reflect"
+)
 
 // 绑定映射模型
 type wiredModel struct {
-	metaType      reflect.Type  // 元类型
-	priority      int           // 优先级
-	proxy         bool          // 是否为代理类型
-	target        interface{}   // 实例
-	reflectTarget reflect.Value // 反射值
-	injected      bool          // 是否注入完成
-	injecting     bool          // 依赖注入中
+	metaType      reflect.Type    // 元类型
+	name          string          // 绑定名称
+	priority      int             // 优先级
+	proxy         bool            // 是否为代理类型
+	target        interface{}     // 实例
+	reflectTarget reflect.Value   // 反射值
+	injected      bool            // 是否注入完成
+	injecting     bool            // 依赖注入中
+	provider      reflect.Value   // 构造函数
+	constructing  bool            // 构造中
+	params        []*wiredModel   // 构造函数参数依赖
+	conditions    []*condition    // 绑定条件
+	owner         *Container      // 所属容器
+	scope         Scope           // 作用域
+	origin        *wiredModel     // 原型及请求作用域实例对应的绑定
+	ctx           context.Context // 创建原型及请求作用域实例时的请求上下文
 }
 
 func newWiredModel(metaType reflect.Type, target interface{}, proxy bool, priority int) *wiredModel {
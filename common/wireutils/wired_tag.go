@@ -0,0 +1,51 @@
+package wireutils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 注入标签
+// `wired:"Autowired"`
+// `wired:"Autowired,name=primary"` 注入名称为primary的实例
+// `wired:"Autowired,optional"` 没有绑定时保持字段为nil
+// `wired:"Autowired,lazy"` 字段类型为 func() T, 首次调用时解析实例
+type wiredTag struct {
+	name     string // 限定名称
+	optional bool   // 可选注入
+	lazy     bool   // 延迟注入
+}
+
+// 解析注入标签, 非Autowired标签返回nil
+func parseWiredTag(tag string) (*wiredTag, error) {
+	parts := strings.Split(tag, ",")
+	if strings.ToLower(strings.TrimSpace(parts[0])) != "autowired" {
+		return nil, nil
+	}
+
+	wt := &wiredTag{}
+	for _, part := range parts[1:] {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		val := ""
+		if len(kv) > 1 {
+			val = strings.TrimSpace(kv[1])
+		}
+
+		switch key {
+		case "name":
+			if val == "" {
+				return nil, fmt.Errorf("invalid wired tag %q, name must not be empty", tag)
+			}
+			wt.name = val
+		case "optional":
+			wt.optional = true
+		case "lazy":
+			wt.lazy = true
+		default:
+			return nil, fmt.Errorf("invalid wired tag %q, unknown option %s", tag, key)
+		}
+	}
+
+	return wt, nil
+}
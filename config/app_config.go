@@ -1,26 +1,46 @@
 // 配置文件包
+//
+// 配置由application.yml和profile对应的application-{profile}.yml组成,
+// profile支持逗号分隔的多个值, 按顺序深度合并到application.yml之上
+//
+// 开启consul.config.enabled后, 从consul kv读取 {prefix}/{server.name}/data 及 {prefix}/{server.name},{profile}/data 并合并到配置文件之上
+//
+// 配置优先级(由低到高): 结构体default标签默认值 < 配置文件 < consul kv < 环境变量
+// 配置文件中的字符串支持 ${VAR:default} 占位符, 加载时使用环境变量VAR替换, 不存在时使用default
+// 加载完成后校验必填项及配置间的约束, 校验失败时由MustLoad输出所有问题并退出程序
+// 所有配置项均可通过环境变量覆盖, 变量名为 LOOKLAPI_ 加上配置路径的大写形式, 如 redis.password 对应 LOOKLAPI_REDIS_PASSWORD
 package config
 
 import (
 	"fmt"
-	"gopkg.in/yaml.v2"
-	"io/ioutil"
+	"os"
+	"sync/atomic"
 )
 
-// 应用程序配置
-var AppConfig = &appConfig
+// 当前的配置快照
+// 重新加载时整体替换为新的快照, 快照本身不会被修改, 可在任意goroutine中无锁读取
+var current atomic.Pointer[applicationConfig]
+
+// 应用程序配置, 返回当前的配置快照
+// 同一次处理中需读取多个配置项时, 应先保存快照再读取, 以保证配置项之间的一致性
+//
+//	cfg := config.AppConfig()
+//	address := cfg.Redis.Host + ":" + cfg.Redis.Port
+func AppConfig() *applicationConfig {
+	return current.Load()
+}
 
 type commConfig struct {
-	Profile string `yaml:"profile"`
+	Profile string `yaml:"profile" default:"dev"`
 
 	Server struct {
-		Name string `yaml:"name"`
-		Port string `yaml:"port"`
+		Name string `yaml:"name" default:"looklapi" validate:"required"`
+		Port string `yaml:"port" default:"8001" validate:"required"`
 	} `yaml:"server"`
 }
 
-// 程序配置
-var appConfig = struct {
+// 程序配置模型
+type applicationConfig struct {
 	*commConfig
 	MySql      string `yaml:"mysql"`
 	MongodbUri string `yaml:"mongodb_uri"`
@@ -30,7 +50,7 @@ var appConfig = struct {
 		Port     string `yaml:"port"`
 		Password string `yaml:"password"`
 		// 超时时间 毫秒
-		Timeout int32 `yaml:"timeout"`
+		Timeout int32 `yaml:"timeout" default:"10000"`
 	} `yaml:"redis"`
 
 	Rabbitmq struct {
@@ -41,58 +61,45 @@ var appConfig = struct {
 		Host                           string `yaml:"host"`
 		Port                           string `yaml:"port"`
 		Secure                         bool   `yaml:"secure"`
-		HealthCheck                    string `yaml:"health-check"`
-		Timeout                        string `yaml:"timeout"`
-		Interval                       string `yaml:"interval"`
-		DeregisterCriticalServiceAfter string `yaml:"deregister-critical-service-after"`
+		HealthCheck                    string `yaml:"health-check" default:"/service/healthCheck"`
+		Timeout                        string `yaml:"timeout" default:"5s"`
+		Interval                       string `yaml:"interval" default:"10s"`
+		DeregisterCriticalServiceAfter string `yaml:"deregister-critical-service-after" default:"600s"`
+
+		// consul kv配置中心
+		Config struct {
+			Enabled  bool   `yaml:"enabled"`
+			Prefix   string `yaml:"prefix" default:"config"`
+			FailFast bool   `yaml:"fail-fast"`
+		} `yaml:"config"`
 	} `yaml:"consul"`
 
 	Logger struct {
-		DefaultLogger string `yaml:"default-logger"`
-		InitLevel     string `yaml:"init-level"`
+		DefaultLogger string `yaml:"default-logger" default:"file"`
+		InitLevel     string `yaml:"init-level" default:"info"`
 	} `yaml:"logger"`
-}{}
+}
 
 func init() {
-	bytes, err := ioutil.ReadFile("application.yml")
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-
-	cc := &commConfig{}
-	if err := yaml.Unmarshal(bytes, cc); err != nil {
-		fmt.Println(err)
-		return
+	// 尽早加载配置, 使依赖配置的包在初始化时读取到配置
+	// 加载失败时使用默认值, 由main通过MustLoad检查并退出, 不在包初始化时退出以便其他包可独立测试
+	if err := load(); err != nil {
+		defaults := &applicationConfig{commConfig: &commConfig{}}
+		applyDefaults(defaults)
+		current.Store(defaults)
 	}
+}
 
-	if cc.Profile != "prod" {
-		devConfbytes, err := ioutil.ReadFile("application-dev.yml")
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-
-		if err := yaml.Unmarshal(devConfbytes, AppConfig); err != nil {
-			fmt.Println(err)
-			return
-		}
-
-		AppConfig.commConfig = cc
-
-	} else {
-		prodConfbytes, err := ioutil.ReadFile("application-prod.yml")
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-
-		if err := yaml.Unmarshal(prodConfbytes, AppConfig); err != nil {
-			fmt.Println(err)
-			return
-		}
+// 加载配置, 校验失败时返回所有问题并保留原配置
+func Load() error {
+	return load()
+}
 
-		AppConfig.commConfig = cc
+// 加载配置, 配置错误时立即退出, 避免运行中出现难以定位的错误
+// 由main在启动时调用
+func MustLoad() {
+	if err := Load(); err != nil {
+		fmt.Fprintln(os.Stderr, "load config failed:", err.Error())
+		os.Exit(1)
 	}
-
 }
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	consulApi "github.com/hashicorp/consul/api"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// 阻塞查询最长等待时间
+	consulWaitTime = 5 * time.Minute
+	// 查询失败重试间隔
+	consulRetryInterval = 5 * time.Second
+)
+
+// consul kv配置源
+// 以 {prefix}/{server.name} 为前缀使用阻塞查询监听配置变更
+type consulSource struct {
+	mu      *sync.Mutex
+	client  *consulApi.Client
+	address string            // consul地址
+	prefix  string            // 查询前缀
+	index   uint64            // 阻塞查询索引
+	values  map[string][]byte // 已读取的配置 key -> yaml
+	loaded  bool              // 是否已读取
+}
+
+var remote = &consulSource{mu: &sync.Mutex{}}
+
+// 是否开启consul配置中心
+func consulEnabled(app *applicationConfig) bool {
+	return app.Consul.Config.Enabled && app.Consul.Host != "" && app.Consul.Port != ""
+}
+
+// consul查询前缀 {prefix}/{server.name}
+func consulPrefix(app *applicationConfig) string {
+	return fmt.Sprintf("%s/%s", strings.Trim(app.Consul.Config.Prefix, "/"), app.Server.Name)
+}
+
+// 配置对应的consul key, 按合并顺序排列
+func consulKeys(app *applicationConfig, profiles []string) []string {
+	base := consulPrefix(app)
+	keys := []string{base + "/data"}
+	for _, profile := range profiles {
+		keys = append(keys, fmt.Sprintf("%s,%s/data", base, profile))
+	}
+	return keys
+}
+
+// 读取consul配置树, 按合并顺序排列
+// 首次读取或consul地址变化时查询consul, 否则使用监听更新的缓存
+func (source *consulSource) trees(app *applicationConfig, profiles []string) ([]map[string]interface{}, error) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	address := app.Consul.Host + ":" + app.Consul.Port
+	prefix := consulPrefix(app)
+	if !source.loaded || source.address != address || source.prefix != prefix {
+		if err := source.connect(address, prefix); err != nil {
+			return nil, err
+		}
+		if err := source.fetch(); err != nil {
+			return nil, err
+		}
+	}
+
+	var trees []map[string]interface{}
+	for _, key := range consulKeys(app, profiles) {
+		value, ok := source.values[key]
+		if !ok || len(bytes.TrimSpace(value)) == 0 {
+			continue
+		}
+
+		tree, err := parseTree(value, "consul key "+key)
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, tree)
+	}
+
+	return trees, nil
+}
+
+// 创建consul客户端
+func (source *consulSource) connect(address string, prefix string) error {
+	consulConfig := consulApi.DefaultConfig()
+	consulConfig.Address = address
+	consulConfig.Scheme = "http"
+
+	client, err := consulApi.NewClient(consulConfig)
+	if err != nil {
+		return err
+	}
+
+	source.client = client
+	source.address = address
+	source.prefix = prefix
+	source.index = 0
+	source.loaded = false
+	return nil
+}
+
+// 查询前缀下的所有配置
+func (source *consulSource) fetch() error {
+	values, index, err := listValues(context.Background(), source.client, source.prefix, 0)
+	if err != nil {
+		return err
+	}
+
+	source.values = values
+	source.index = index
+	source.loaded = true
+	return nil
+}
+
+// 监听consul配置变更, 变更后重新加载配置, ctx取消后停止
+func (source *consulSource) watch(ctx context.Context) {
+	for ctx.Err() == nil {
+		source.mu.Lock()
+		client, prefix, index := source.client, source.prefix, source.index
+		source.mu.Unlock()
+
+		if client == nil || !consulEnabled(AppConfig()) {
+			sleep(ctx, consulRetryInterval)
+			continue
+		}
+
+		// 阻塞查询期间不持有锁
+		values, latestIndex, err := listValues(ctx, client, prefix, index)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			fmt.Println(err)
+			sleep(ctx, consulRetryInterval)
+			continue
+		}
+
+		source.mu.Lock()
+		if source.client != client || source.prefix != prefix {
+			// 查询期间consul地址变化, 丢弃结果
+			source.mu.Unlock()
+			continue
+		}
+		if latestIndex < index {
+			// consul索引重置, 重新开始阻塞查询
+			latestIndex = 0
+		}
+		changed := !equalValues(source.values, values)
+		source.values = values
+		source.index = latestIndex
+		source.loaded = true
+		source.mu.Unlock()
+
+		if changed {
+			if _, err := Reload(); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+}
+
+// 等待d或ctx取消
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// 查询前缀下的所有配置
+// waitIndex大于0时为阻塞查询, 直到配置变更, 超时或ctx取消返回
+func listValues(ctx context.Context, client *consulApi.Client, prefix string, waitIndex uint64) (map[string][]byte, uint64, error) {
+	options := &consulApi.QueryOptions{
+		WaitIndex: waitIndex,
+		WaitTime:  consulWaitTime,
+	}
+	pairs, meta, err := client.KV().List(prefix, options.WithContext(ctx))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	values := make(map[string][]byte, len(pairs))
+	for _, pair := range pairs {
+		values[pair.Key] = pair.Value
+	}
+	return values, meta.LastIndex, nil
+}
+
+func equalValues(old map[string][]byte, latest map[string][]byte) bool {
+	if len(old) != len(latest) {
+		return false
+	}
+	for key, val := range latest {
+		if oldVal, ok := old[key]; !ok || !bytes.Equal(oldVal, val) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 模拟consul kv接口, 支持阻塞查询
+type fakeConsul struct {
+	mu      *sync.Mutex
+	changed *sync.Cond
+	index   uint64
+	values  map[string]string
+}
+
+func newFakeConsul(values map[string]string) *fakeConsul {
+	fake := &fakeConsul{mu: &sync.Mutex{}, index: 1, values: values}
+	fake.changed = sync.NewCond(fake.mu)
+	return fake
+}
+
+// 修改配置并增加索引, 唤醒阻塞查询
+func (fake *fakeConsul) put(key string, value string) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.values[key] = value
+	fake.index++
+	fake.changed.Broadcast()
+}
+
+func (fake *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	waitIndex, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+
+	fake.mu.Lock()
+	if waitIndex > 0 && waitIndex == fake.index {
+		// 索引未变化时等待变更, 最多等待1秒, 请求取消时立即返回
+		wake := func() {
+			fake.mu.Lock()
+			fake.changed.Broadcast()
+			fake.mu.Unlock()
+		}
+		timer := time.AfterFunc(time.Second, wake)
+		stop := context.AfterFunc(r.Context(), wake)
+		fake.changed.Wait()
+		timer.Stop()
+		stop()
+	}
+
+	type pair struct {
+		Key   string
+		Value []byte
+	}
+	var pairs []pair
+	for key, value := range fake.values {
+		if strings.HasPrefix(key, prefix) {
+			pairs = append(pairs, pair{Key: key, Value: []byte(value)})
+		}
+	}
+	index := fake.index
+	fake.mu.Unlock()
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(index, 10))
+	if len(pairs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pairs)
+}
+
+// 写入application.yml并使用consul配置中心
+func setupConsulConfig(t *testing.T, address string, failFast bool) {
+	host, port, _ := strings.Cut(strings.TrimPrefix(address, "http://"), ":")
+	content := "profile: dev\n" +
+		"server:\n  name: looklapi\n  port: 8001\n" +
+		"redis:\n  host: file-host\n  port: 6379\n" +
+		"consul:\n  host: " + host + "\n  port: " + port + "\n" +
+		"  config:\n    enabled: true\n    prefix: config\n    fail-fast: " + strconv.FormatBool(failFast) + "\n"
+
+	dir := t.TempDir()
+	writeBaseConfig(t, dir, content)
+	t.Setenv(configDirEnv, dir)
+	remote = &consulSource{mu: &sync.Mutex{}}
+}
+
+func TestConsulSourceLayering(t *testing.T) {
+	fake := newFakeConsul(map[string]string{
+		"config/looklapi/data":     "redis:\n  host: base-host\n  port: 6380\n",
+		"config/looklapi,dev/data": "redis:\n  host: dev-host\n",
+		"config/looklapi,prd/data": "redis:\n  host: prd-host\n",
+	})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	setupConsulConfig(t, server.URL, true)
+
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := AppConfig()
+	if cfg.Redis.Host != "dev-host" {
+		t.Errorf("redis.host = %s, want dev-host", cfg.Redis.Host)
+	}
+	if cfg.Redis.Port != "6380" {
+		t.Errorf("redis.port = %s, want 6380", cfg.Redis.Port)
+	}
+	if val, _ := Lookup("redis.host"); val != "dev-host" {
+		t.Errorf("Lookup(redis.host) = %s, want dev-host", val)
+	}
+}
+
+func TestConsulSourceFailFast(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	setupConsulConfig(t, server.URL, true)
+	if err := Load(); err == nil {
+		t.Error("Load() with fail-fast should return the consul error")
+	}
+
+	setupConsulConfig(t, server.URL, false)
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	if host := AppConfig().Redis.Host; host != "file-host" {
+		t.Errorf("redis.host = %s, want file-host", host)
+	}
+}
+
+func TestConsulSourceWatch(t *testing.T) {
+	fake := newFakeConsul(map[string]string{
+		"config/looklapi/data": "redis:\n  host: base-host\n",
+	})
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	setupConsulConfig(t, server.URL, true)
+
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 测试结束时先停止监听, 再关闭consul
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		remote.watch(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+
+	fake.put("config/looklapi,dev/data", "redis:\n  host: changed-host\n")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for AppConfig().Redis.Host != "changed-host" {
+		if time.Now().After(deadline) {
+			t.Fatalf("redis.host = %s, want changed-host after consul index changed", AppConfig().Redis.Host)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
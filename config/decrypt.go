@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"looklapi/config/secret"
+	"sort"
+)
+
+// 解密配置树中所有 ENC(...) 形式的字符串
+func decryptTree(tree map[string]interface{}) error {
+	if !hasEncrypted(tree) {
+		return nil
+	}
+
+	key, err := secret.LoadKey()
+	if err != nil {
+		return err
+	}
+
+	v := &validator{}
+	decryptNode(tree, "", key, v)
+	return v.err()
+}
+
+// 是否包含加密值
+func hasEncrypted(node interface{}) bool {
+	switch n := node.(type) {
+	case string:
+		return secret.IsEncrypted(n)
+	case map[string]interface{}:
+		for _, child := range n {
+			if hasEncrypted(child) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, child := range n {
+			if hasEncrypted(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decryptNode(node interface{}, path string, key string, v *validator) interface{} {
+	switch n := node.(type) {
+	case string:
+		if !secret.IsEncrypted(n) {
+			return n
+		}
+		plain, err := secret.Decrypt(n, key)
+		if err != nil {
+			v.addf("%s decrypt failed: %s", path, err.Error())
+			return n
+		}
+		return plain
+	case map[string]interface{}:
+		keys := make([]string, 0, len(n))
+		for k := range n {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			n[k] = decryptNode(n[k], childPath, key, v)
+		}
+		return n
+	case []interface{}:
+		for i, child := range n {
+			n[i] = decryptNode(child, fmt.Sprintf("%s[%d]", path, i), key, v)
+		}
+		return n
+	default:
+		return node
+	}
+}
+
+// 解密环境变量及命令行参数中的 ENC(...) 值
+// source 配置来源, 用于错误信息
+func decryptValue(source string, val string) (string, error) {
+	if !secret.IsEncrypted(val) {
+		return val, nil
+	}
+
+	key, err := secret.LoadKey()
+	if err != nil {
+		return "", err
+	}
+
+	plain, err := secret.Decrypt(val, key)
+	if err != nil {
+		return "", fmt.Errorf("%s decrypt failed: %s", source, err.Error())
+	}
+	return plain, nil
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 环境变量前缀
+const envPrefix = "LOOKLAPI_"
+
+// 占位符 ${VAR} 或 ${VAR:default}
+var placeholderRegexp = regexp.MustCompile(`\$\{([A-Za-z0-9_.\-]+)(?::([^}]*))?}`)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// 替换字符串中的占位符, 替换后的值保持为字符串, 解析到结构体时按字段类型转换
+func resolvePlaceholders(value string) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+
+	return placeholderRegexp.ReplaceAllStringFunc(value, func(match string) string {
+		groups := placeholderRegexp.FindStringSubmatch(match)
+		if val, ok := os.LookupEnv(groups[1]); ok {
+			return val
+		}
+		return groups[2]
+	})
+}
+
+// 配置路径对应的环境变量名
+// redis.password -> LOOKLAPI_REDIS_PASSWORD, consul.health-check -> LOOKLAPI_CONSUL_HEALTH_CHECK
+func envName(path []string) string {
+	name := strings.ToUpper(strings.Join(path, "_"))
+	name = strings.NewReplacer("-", "_", ".", "_").Replace(name)
+	return envPrefix + name
+}
+
+// 使用default标签设置默认值
+func applyDefaults(target interface{}) error {
+	return walkFields(reflect.ValueOf(target), nil, func(path []string, field reflect.Value, sf reflect.StructField) error {
+		def, ok := sf.Tag.Lookup("default")
+		if !ok || !field.IsZero() {
+			return nil
+		}
+		if err := setFieldValue(field, def); err != nil {
+			return fmt.Errorf("invalid default value of %s: %s", strings.Join(path, "."), err.Error())
+		}
+		return nil
+	})
+}
+
+// 使用环境变量覆盖配置
+// prefix 配置路径前缀, 解析配置段时为配置段路径
+func applyEnvOverrides(target interface{}, prefix ...string) error {
+	return walkFields(reflect.ValueOf(target), prefix, func(path []string, field reflect.Value, sf reflect.StructField) error {
+		val, ok := os.LookupEnv(envName(path))
+		if !ok {
+			return nil
+		}
+		val, err := decryptValue(envName(path), val)
+		if err != nil {
+			return err
+		}
+		if err := setFieldValue(field, val); err != nil {
+			return fmt.Errorf("invalid env %s: %s", envName(path), err.Error())
+		}
+		return nil
+	})
+}
+
+// 遍历导出的叶子字段, 字段名与yaml解析规则一致, 无yaml标签时为小写的字段名
+func walkFields(val reflect.Value, path []string, fn func(path []string, field reflect.Value, sf reflect.StructField) error) error {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	vtype := val.Type()
+	for i := 0; i < vtype.NumField(); i++ {
+		sf := vtype.Field(i)
+		name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if name == "-" || !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+
+		field := val.Field(i)
+		fieldPath := append(append([]string{}, path...), name)
+		if field.Kind() == reflect.Struct {
+			if err := walkFields(field, fieldPath, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := fn(fieldPath, field, sf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 将字符串转换为字段类型并赋值
+func setFieldValue(field reflect.Value, val string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.Slice {
+			return fmt.Errorf("unsupported type %s", field.Type().String())
+		}
+		items := strings.Split(val, ",")
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setFieldValue(slice.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type().String())
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResolvePlaceholders(t *testing.T) {
+	t.Setenv("TEST_REDIS_PW", "1.50")
+	t.Setenv("TEST_MONGO_PW", "abc #1")
+	t.Setenv("TEST_MQ_PW", "a: b")
+	t.Setenv("TEST_REDIS_TIMEOUT", "3000")
+
+	content := "redis:\n" +
+		"  password: ${TEST_REDIS_PW}\n" +
+		"  port: ${TEST_REDIS_PORT:0123}\n" +
+		"  timeout: ${TEST_REDIS_TIMEOUT}\n" +
+		"mongodb_uri: ${TEST_MONGO_PW}\n" +
+		"rabbitmq:\n  address: amqp://${TEST_MQ_PW}@host\n" +
+		"consul:\n  secure: ${TEST_CONSUL_SECURE:true}\n"
+	tree, err := parseTree([]byte(content), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if val, _ := lookupTree(tree, []string{"redis", "password"}); val != "1.50" {
+		t.Errorf("redis.password in tree = %v, want 1.50", val)
+	}
+
+	app := &applicationConfig{}
+	if err := decodeTree(tree, app); err != nil {
+		t.Fatal(err)
+	}
+	if app.Redis.Password != "1.50" {
+		t.Errorf("redis.password = %s, want 1.50", app.Redis.Password)
+	}
+	if app.Redis.Port != "0123" {
+		t.Errorf("redis.port = %s, want 0123", app.Redis.Port)
+	}
+	if app.Redis.Timeout != 3000 {
+		t.Errorf("redis.timeout = %d, want 3000", app.Redis.Timeout)
+	}
+	if app.MongodbUri != "abc #1" {
+		t.Errorf("mongodb_uri = %s, want abc #1", app.MongodbUri)
+	}
+	if app.Rabbitmq.Address != "amqp://a: b@host" {
+		t.Errorf("rabbitmq.address = %s, want amqp://a: b@host", app.Rabbitmq.Address)
+	}
+	if !app.Consul.Secure {
+		t.Error("consul.secure = false, want true")
+	}
+}
+
+func TestDecodeSectionPlaceholders(t *testing.T) {
+	t.Setenv("TEST_PAY_TIMEOUT", "3s")
+	t.Setenv("TEST_PAY_RATE", "1.50")
+
+	type payConfig struct {
+		Timeout time.Duration `yaml:"timeout"`
+		Rate    string        `yaml:"rate"`
+	}
+	tree, err := parseTree([]byte("pay:\n  timeout: ${TEST_PAY_TIMEOUT}\n  rate: ${TEST_PAY_RATE}\n"), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := decodeSection(tree, "pay", reflect.TypeOf(payConfig{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pay := decoded.Interface().(payConfig)
+	if pay.Timeout != 3*time.Second || pay.Rate != "1.50" {
+		t.Errorf("decoded %+v, want timeout 3s rate 1.50", pay)
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// 配置目录环境变量
+const configDirEnv = envPrefix + "CONFIG_DIR"
+
+// 命令行参数
+// --profile      激活的profile, 等同于环境变量 LOOKLAPI_PROFILE
+// --config-dir   配置文件目录, 等同于环境变量 LOOKLAPI_CONFIG_DIR, 默认为当前工作目录
+// --port         服务端口, 等同于环境变量 LOOKLAPI_SERVER_PORT
+// --set key=val  覆盖任意配置项, 可重复使用, 如 --set redis.host=127.0.0.1
+type cmdOptions struct {
+	configDir string
+	overrides map[string]string // 配置路径 -> 值
+	keys      []string          // 按参数顺序排列的配置路径
+}
+
+var cmdArgs = parseArgs(os.Args[1:])
+
+// 解析命令行参数, 忽略无法识别的参数
+func parseArgs(args []string) *cmdOptions {
+	options := &cmdOptions{overrides: make(map[string]string)}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		val, hasVal := "", false
+		if idx := strings.Index(name, "="); idx >= 0 {
+			name, val, hasVal = name[:idx], name[idx+1:], true
+		}
+
+		switch name {
+		case "profile", "config-dir", "port", "set":
+		default:
+			continue
+		}
+
+		if !hasVal {
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "flag --%s requires a value\n", name)
+				continue
+			}
+			i++
+			val = args[i]
+		}
+
+		switch name {
+		case "profile":
+			options.set("profile", val)
+		case "config-dir":
+			options.configDir = val
+		case "port":
+			options.set("server.port", val)
+		case "set":
+			kv := strings.SplitN(val, "=", 2)
+			if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+				fmt.Fprintf(os.Stderr, "invalid flag --set %s, must be key=value\n", val)
+				continue
+			}
+			options.set(strings.TrimSpace(kv[0]), kv[1])
+		}
+	}
+
+	return options
+}
+
+func (options *cmdOptions) set(key string, val string) {
+	if _, ok := options.overrides[key]; !ok {
+		options.keys = append(options.keys, key)
+	}
+	options.overrides[key] = val
+}
+
+// 配置文件目录, 命令行参数优先于环境变量
+func configDir() string {
+	if cmdArgs.configDir != "" {
+		return cmdArgs.configDir
+	}
+	if dir, ok := os.LookupEnv(configDirEnv); ok && dir != "" {
+		return dir
+	}
+	return "."
+}
+
+// 配置文件路径
+func configPath(fileName string) string {
+	return filepath.Join(configDir(), fileName)
+}
+
+// 命令行参数覆盖的配置树
+func argsTree() map[string]interface{} {
+	tree := make(map[string]interface{})
+	for _, key := range cmdArgs.keys {
+		var val interface{}
+		if err := yaml.Unmarshal([]byte(cmdArgs.overrides[key]), &val); err != nil || val == nil {
+			val = cmdArgs.overrides[key]
+		}
+
+		path := strings.Split(key, ".")
+		node := tree
+		for _, p := range path[:len(path)-1] {
+			child, ok := node[p].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[p] = child
+			}
+			node = child
+		}
+		node[path[len(path)-1]] = normalizeTree(val)
+	}
+	return tree
+}
+
+// 使用命令行参数覆盖配置, 优先级高于环境变量
+// prefix 配置路径前缀, 解析配置段时为配置段路径
+func applyArgOverrides(target interface{}, prefix ...string) error {
+	if len(cmdArgs.keys) == 0 {
+		return nil
+	}
+
+	return walkFields(reflect.ValueOf(target), prefix, func(path []string, field reflect.Value, sf reflect.StructField) error {
+		key := strings.Join(path, ".")
+		val, ok := cmdArgs.overrides[key]
+		if !ok {
+			return nil
+		}
+		val, err := decryptValue("--set "+key, val)
+		if err != nil {
+			return err
+		}
+		if err := setFieldValue(field, val); err != nil {
+			return fmt.Errorf("invalid flag value of %s: %s", key, err.Error())
+		}
+		return nil
+	})
+}
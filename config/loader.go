@@ -0,0 +1,339 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// 基础配置文件
+const baseConfigFile = "application.yml"
+
+// 合并后的配置树
+var configTree = make(map[string]interface{})
+
+// 激活的profile
+var activeProfiles []string
+
+// 参与合并的配置文件
+var configFiles []string
+
+// 配置更新锁
+var mu = &sync.RWMutex{}
+
+// 配置加载锁, 文件监听与consul监听同时重新加载时按顺序完成读取, 比较, 应用及发布
+var reloadMu = &sync.Mutex{}
+
+// 配置快照
+type snapshot struct {
+	tree     map[string]interface{} // 合并后的配置树
+	profiles []string               // 激活的profile
+	files    []string               // 参与合并的配置文件, 包含尚不存在的profile配置文件
+	cc       *commConfig
+	app      *applicationConfig
+}
+
+// 加载配置
+func load() error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	s, err := loadSnapshot()
+	if err != nil {
+		return err
+	}
+
+	apply(s)
+	return nil
+}
+
+// 读取配置快照
+// 先加载application.yml, 再按顺序将application-{profile}.yml深度合并到基础配置之上
+// 开启consul配置中心时, 再按顺序合并consul kv中的配置, 最后合并命令行参数
+func loadSnapshot() (*snapshot, error) {
+	baseFile := configPath(baseConfigFile)
+	tree, err := readConfigTree(baseFile)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %s", baseFile, err.Error())
+	}
+
+	cc := &commConfig{}
+	if err := decodeTree(tree, cc); err != nil {
+		return nil, err
+	}
+
+	profiles := parseProfiles(cc.Profile)
+	files := []string{baseFile}
+	for _, profile := range profiles {
+		fileName := profileConfigFile(profile)
+		files = append(files, fileName)
+
+		profileTree, err := readConfigTree(fileName)
+		if os.IsNotExist(err) {
+			// profile可能只在consul kv中配置, 缺少配置文件时提示
+			fmt.Printf("profile %s: config file %s not found\n", profile, fileName)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tree = mergeTree(tree, profileTree)
+	}
+
+	// 合并consul kv配置
+	app := &applicationConfig{}
+	if err := decodeTree(tree, app); err != nil {
+		return nil, err
+	}
+	if consulEnabled(app) {
+		app.commConfig = cc
+		remoteTrees, err := remote.trees(app, profiles)
+		if err != nil {
+			if app.Consul.Config.FailFast {
+				return nil, err
+			}
+			fmt.Println(err)
+		}
+		for _, remoteTree := range remoteTrees {
+			tree = mergeTree(tree, remoteTree)
+		}
+	}
+
+	// 合并命令行参数, 使配置树与最终配置一致
+	overrides := argsTree()
+	if err := decryptTree(overrides); err != nil {
+		return nil, fmt.Errorf("command line: %s", err.Error())
+	}
+	tree = mergeTree(tree, overrides)
+
+	app = &applicationConfig{}
+	if err := decodeTree(tree, app); err != nil {
+		return nil, err
+	}
+
+	if err := decodeTree(tree, cc); err != nil {
+		return nil, err
+	}
+	app.commConfig = cc
+
+	if err := validate(app); err != nil {
+		return nil, err
+	}
+
+	return &snapshot{
+		tree:     tree,
+		profiles: profiles,
+		files:    files,
+		cc:       cc,
+		app:      app,
+	}, nil
+}
+
+// 应用配置快照
+func apply(s *snapshot) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	current.Store(s.app)
+	configTree = s.tree
+	activeProfiles = s.profiles
+	configFiles = s.files
+}
+
+// profile对应的配置文件
+func profileConfigFile(profile string) string {
+	return configPath(fmt.Sprintf("application-%s.yml", profile))
+}
+
+// 解析逗号分隔的profile列表
+func parseProfiles(profile string) []string {
+	var profiles []string
+	for _, p := range strings.Split(profile, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		duplicated := false
+		for _, exist := range profiles {
+			if exist == p {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated {
+			profiles = append(profiles, p)
+		}
+	}
+
+	return profiles
+}
+
+// 读取配置文件为配置树
+func readConfigTree(fileName string) (map[string]interface{}, error) {
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTree(bytes, fileName)
+}
+
+// 解析yaml内容为配置树
+// 替换占位符并解密 ENC(...) 值
+// source 配置来源, 用于错误信息
+func parseTree(content []byte, source string) (map[string]interface{}, error) {
+	raw := make(map[interface{}]interface{})
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %s", source, err.Error())
+	}
+
+	tree := normalizeTree(raw).(map[string]interface{})
+	if err := decryptTree(tree); err != nil {
+		return nil, fmt.Errorf("%s: %s", source, err.Error())
+	}
+	return tree, nil
+}
+
+// 将配置树解析到结构体
+// 依次应用default标签默认值, 配置树, 环境变量, 命令行参数
+func decodeTree(tree map[string]interface{}, target interface{}) error {
+	if err := applyDefaults(target); err != nil {
+		return err
+	}
+
+	if err := decodeNode(tree, target); err != nil {
+		return err
+	}
+
+	if err := applyEnvOverrides(target); err != nil {
+		return err
+	}
+	return applyArgOverrides(target)
+}
+
+// 将配置节点解析到结构体
+// 非字符串字段的配置值为字符串时(如占位符替换后的值)按字段类型转换, 其余配置按yaml解析
+func decodeNode(node map[string]interface{}, target interface{}) error {
+	var scalars []func() error
+	rest := node
+	walkFields(reflect.ValueOf(target), nil, func(path []string, field reflect.Value, sf reflect.StructField) error {
+		if field.Kind() == reflect.String {
+			return nil
+		}
+		val, ok := lookupTree(node, path)
+		if str, isStr := val.(string); ok && isStr {
+			rest = withoutPath(rest, path)
+			scalars = append(scalars, func() error {
+				if err := setFieldValue(field, str); err != nil {
+					return fmt.Errorf("invalid config %s: %s", strings.Join(path, "."), err.Error())
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+
+	bytes, err := yaml.Marshal(rest)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(bytes, target); err != nil {
+		return err
+	}
+
+	for _, set := range scalars {
+		if err := set(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 移除配置树中的节点, 返回新的配置树
+func withoutPath(tree map[string]interface{}, path []string) map[string]interface{} {
+	result := make(map[string]interface{}, len(tree))
+	for k, v := range tree {
+		result[k] = v
+	}
+
+	if len(path) == 1 {
+		delete(result, path[0])
+	} else if child, ok := result[path[0]].(map[string]interface{}); ok {
+		result[path[0]] = withoutPath(child, path[1:])
+	}
+	return result
+}
+
+// 将yaml解析的map[interface{}]interface{}转换为map[string]interface{}
+// 字符串值中的占位符在解析后替换, 环境变量中的特殊字符不影响yaml解析
+func normalizeTree(node interface{}) interface{} {
+	switch n := node.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(n))
+		for k, v := range n {
+			result[fmt.Sprintf("%v", k)] = normalizeTree(v)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(n))
+		for k, v := range n {
+			result[k] = normalizeTree(v)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(n))
+		for i, v := range n {
+			result[i] = normalizeTree(v)
+		}
+		return result
+	case string:
+		return resolvePlaceholders(n)
+	default:
+		return node
+	}
+}
+
+// 深度合并配置树, override中的值覆盖base, 返回新的配置树
+// 仅合并map, 列表及标量直接替换
+func mergeTree(base map[string]interface{}, override map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		result[k] = v
+	}
+
+	for k, v := range override {
+		baseMap, baseOk := result[k].(map[string]interface{})
+		overrideMap, overrideOk := v.(map[string]interface{})
+		if baseOk && overrideOk {
+			result[k] = mergeTree(baseMap, overrideMap)
+		} else {
+			result[k] = v
+		}
+	}
+
+	return result
+}
+
+// 激活的profile列表, 按合并顺序排列
+func ActiveProfiles() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]string{}, activeProfiles...)
+}
+
+// profile是否激活
+func IsProfileActive(profile string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, p := range activeProfiles {
+		if p == profile {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// 按路径读取配置项, 如 redis.host
+// 环境变量 LOOKLAPI_REDIS_HOST 优先于配置文件, consul及命令行参数合并后的值
+// 配置项为配置段或列表时返回yaml格式的内容
+// 只返回已加载的配置, 不包含default标签的默认值, 需要默认值时使用Value
+func Lookup(key string) (string, bool) {
+	path := strings.Split(key, ".")
+	if val, ok := os.LookupEnv(envName(path)); ok {
+		if decrypted, err := decryptValue(envName(path), val); err == nil {
+			return decrypted, true
+		}
+		return val, true
+	}
+
+	mu.RLock()
+	tree := configTree
+	mu.RUnlock()
+
+	node, ok := lookupTree(tree, path)
+	if !ok {
+		return "", false
+	}
+
+	switch node.(type) {
+	case nil:
+		return "", true
+	case map[string]interface{}, []interface{}:
+		bytes, err := yaml.Marshal(node)
+		if err != nil {
+			return "", true
+		}
+		return string(bytes), true
+	default:
+		return fmt.Sprint(node), true
+	}
+}
+
+// 配置项是否存在于已加载的配置中(配置文件, consul, 环境变量及命令行参数), 不包含default标签的默认值
+func Has(key string) bool {
+	_, ok := Lookup(key)
+	return ok
+}
+
+// 将配置项解析到target指针指向的值, 如 config.Value("redis.timeout", &timeout)
+// 支持基础类型, time.Duration, 切片及结构体, 结构体与BindSection的解析规则一致
+// 环境变量优先于配置文件, 配置项不存在时依次使用default标签的默认值及默认值def, 都不存在时返回错误
+func Value(key string, target interface{}, def ...string) error {
+	tval := reflect.ValueOf(target)
+	if target == nil || tval.Kind() != reflect.Ptr || tval.IsNil() {
+		return errors.New("target must be a pointer")
+	}
+
+	mu.RLock()
+	tree := configTree
+	mu.RUnlock()
+
+	elem := tval.Elem()
+	if elem.Kind() == reflect.Struct {
+		decoded, err := decodeSection(tree, key, elem.Type())
+		if err != nil {
+			return err
+		}
+		elem.Set(decoded)
+		return nil
+	}
+
+	path := strings.Split(key, ".")
+	if val, ok := os.LookupEnv(envName(path)); ok {
+		val, err := decryptValue(envName(path), val)
+		if err != nil {
+			return err
+		}
+		if err := setFieldValue(elem, val); err != nil {
+			return fmt.Errorf("invalid env %s: %s", envName(path), err.Error())
+		}
+		return nil
+	}
+
+	if node, ok := lookupTree(tree, path); ok && node != nil {
+		bytes, err := yaml.Marshal(node)
+		if err != nil {
+			return err
+		}
+		if err := yaml.Unmarshal(bytes, target); err != nil {
+			// 标量配置按逗号分隔解析为切片
+			if _, scalar := node.(string); !scalar || setFieldValue(elem, node.(string)) != nil {
+				return fmt.Errorf("invalid config %s: %s", key, err.Error())
+			}
+		}
+		return nil
+	}
+
+	if val, ok := lookupDefault(path); ok {
+		if err := setFieldValue(elem, val); err != nil {
+			return fmt.Errorf("invalid default value of %s: %s", key, err.Error())
+		}
+		return nil
+	}
+
+	if len(def) > 0 {
+		if err := setFieldValue(elem, def[0]); err != nil {
+			return fmt.Errorf("invalid default value of %s: %s", key, err.Error())
+		}
+		return nil
+	}
+
+	return fmt.Errorf("config %s is not found", key)
+}
+
+// 配置路径对应的default标签默认值
+// 查找配置结构体及绑定的配置段
+func lookupDefault(path []string) (string, bool) {
+	sectionMu.Lock()
+	bound := append([]*section{}, sections...)
+	sectionMu.Unlock()
+
+	key := strings.Join(path, ".")
+	if def, ok := defaultTag(reflect.TypeOf(commConfig{}), nil, key); ok {
+		return def, true
+	}
+	if def, ok := defaultTag(reflect.TypeOf(applicationConfig{}), nil, key); ok {
+		return def, true
+	}
+	for _, sec := range bound {
+		if def, ok := defaultTag(sec.ttype, strings.Split(sec.name, "."), key); ok {
+			return def, true
+		}
+	}
+	return "", false
+}
+
+// 结构体中配置路径为key的字段的default标签
+func defaultTag(ttype reflect.Type, prefix []string, key string) (string, bool) {
+	var def string
+	var found bool
+	walkFields(reflect.New(ttype), prefix, func(path []string, field reflect.Value, sf reflect.StructField) error {
+		if tag, ok := sf.Tag.Lookup("default"); ok && !found && strings.Join(path, ".") == key {
+			def, found = tag, true
+		}
+		return nil
+	})
+	return def, found
+}
@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+func TestLookupIgnoresDefaultTags(t *testing.T) {
+	mu.Lock()
+	tree := configTree
+	configTree = map[string]interface{}{"redis": map[string]interface{}{"host": "127.0.0.1"}}
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		configTree = tree
+		mu.Unlock()
+	})
+
+	if !Has("redis.host") {
+		t.Error("Has(redis.host) = false, want true")
+	}
+	if Has("redis.timeout") || Has("consul.health-check") {
+		t.Error("Has should not report keys only present as default tags")
+	}
+
+	var timeout int
+	if err := Value("redis.timeout", &timeout); err != nil {
+		t.Fatal(err)
+	}
+	if timeout != 10000 {
+		t.Errorf("Value(redis.timeout) = %d, want default 10000", timeout)
+	}
+}
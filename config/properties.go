@@ -0,0 +1,26 @@
+package config
+
+// 配置读取器, 作为依赖注入的配置来源
+//
+//	wireutils.SetPropertySource(config.Properties())
+type properties struct{}
+
+// 配置读取器, 读取当前的配置
+func Properties() properties {
+	return properties{}
+}
+
+// 按路径读取配置项, 同 Lookup
+func (properties) Lookup(key string) (string, bool) {
+	return Lookup(key)
+}
+
+// 将配置项解析到target指针指向的值, 同 Value
+func (properties) Value(key string, target interface{}, def ...string) error {
+	return Value(key, target, def...)
+}
+
+// profile是否激活, 同 IsProfileActive
+func (properties) IsProfileActive(profile string) bool {
+	return IsProfileActive(profile)
+}
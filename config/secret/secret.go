@@ -0,0 +1,90 @@
+// 配置加密包
+// 配置中的 ENC(...) 值使用AES加密并base64编码, 加载配置时使用密钥解密
+// 密钥长度须为16, 24或32位, 分别对应AES-128, AES-192, AES-256
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"looklapi/common/utils"
+	"os"
+	"strings"
+)
+
+const (
+	// 密钥环境变量
+	KeyEnv = "LOOKLAPI_CONFIG_KEY"
+	// 密钥文件路径环境变量
+	KeyFileEnv = "LOOKLAPI_CONFIG_KEY_FILE"
+)
+
+const (
+	encPrefix = "ENC("
+	encSuffix = ")"
+)
+
+// 是否为加密值
+func IsEncrypted(val string) bool {
+	val = strings.TrimSpace(val)
+	return strings.HasPrefix(val, encPrefix) && strings.HasSuffix(val, encSuffix)
+}
+
+// 加密为 ENC(...) 形式
+func Encrypt(plain string, key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+
+	cipherText, err := utils.AesEcrypt2Base64(plain, key, nil)
+	if err != nil {
+		return "", err
+	}
+	return encPrefix + cipherText + encSuffix, nil
+}
+
+// 解密 ENC(...) 形式的值, 非加密值原样返回
+func Decrypt(val string, key string) (string, error) {
+	if !IsEncrypted(val) {
+		return val, nil
+	}
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+
+	val = strings.TrimSpace(val)
+	cipherText := val[len(encPrefix) : len(val)-len(encSuffix)]
+	return utils.AesDecryptBase64(cipherText, key, nil)
+}
+
+// 读取密钥
+// 优先使用环境变量LOOKLAPI_CONFIG_KEY, 其次读取LOOKLAPI_CONFIG_KEY_FILE指定的密钥文件
+// 未配置密钥时返回空字符串
+func LoadKey() (string, error) {
+	if key, ok := os.LookupEnv(KeyEnv); ok && key != "" {
+		return key, nil
+	}
+
+	keyFile, ok := os.LookupEnv(KeyFileEnv)
+	if !ok || keyFile == "" {
+		return "", nil
+	}
+
+	bytes, err := os.ReadFile(keyFile)
+	if err != nil {
+		return "", fmt.Errorf("read config key file: %s", err.Error())
+	}
+	return strings.TrimSpace(string(bytes)), nil
+}
+
+func checkKey(key string) error {
+	if key == "" {
+		return errors.New("config key is not set, please set " + KeyEnv + " or " + KeyFileEnv)
+	}
+
+	switch len(key) {
+	case 16, 24, 32:
+		return nil
+	default:
+		return fmt.Errorf("config key length must be 16, 24 or 32, got %d", len(key))
+	}
+}
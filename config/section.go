@@ -0,0 +1,190 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// 绑定的配置段
+type section struct {
+	name  string                  // 配置段名称
+	ttype reflect.Type            // 配置段结构体类型
+	store func(val reflect.Value) // 保存重新加载后的配置段, BindSection绑定的配置段不更新, 为nil
+}
+
+var sections []*section
+var sectionMu = &sync.Mutex{}
+
+// 将配置段解析到自定义结构体, 只在绑定时解析一次, 配置重新加载后不更新target
+// 需要热更新时使用 WatchSection, 或订阅AppEventConfigChanged事件后通过Value重新读取
+// name 配置段名称, 如 payment, 支持 a.b 形式的嵌套配置段
+// target 结构体指针, 支持default标签默认值, validate:"required"必填校验, 及 LOOKLAPI_{NAME}_{FIELD} 形式的环境变量覆盖
+//
+//	type PaymentConfig struct {
+//		Gateway string        `yaml:"gateway" default:"https://pay.example.com"`
+//		Timeout time.Duration `yaml:"timeout" default:"3s"`
+//	}
+//
+//	var paymentConfig = &PaymentConfig{}
+//	config.BindSection("payment", paymentConfig)
+func BindSection(name string, target interface{}) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("section name must not be empty")
+	}
+
+	tval := reflect.ValueOf(target)
+	if target == nil || tval.Kind() != reflect.Ptr || tval.IsNil() || tval.Elem().Kind() != reflect.Struct {
+		return errors.New("target must be a struct pointer")
+	}
+
+	decoded, err := registerSection(name, tval.Elem().Type(), nil)
+	if err != nil {
+		return err
+	}
+	tval.Elem().Set(decoded)
+	return nil
+}
+
+// 热更新的配置段
+// 配置重新加载后整体替换为新的快照, 快照本身不会被修改, 可在任意goroutine中无锁读取
+type Section[T any] struct {
+	current atomic.Pointer[T]
+}
+
+// 当前的配置段快照
+func (sec *Section[T]) Get() *T {
+	return sec.current.Load()
+}
+
+func (sec *Section[T]) store(val reflect.Value) {
+	ptr := reflect.New(val.Type())
+	ptr.Elem().Set(val)
+	sec.current.Store(ptr.Interface().(*T))
+}
+
+// 将配置段解析到T类型的结构体, 配置重新加载后更新快照
+// 解析规则与BindSection一致
+//
+//	var paymentConfig, _ = config.WatchSection[PaymentConfig]("payment")
+//
+//	gateway := paymentConfig.Get().Gateway
+func WatchSection[T any](name string) (*Section[T], error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("section name must not be empty")
+	}
+
+	ttype := reflect.TypeOf((*T)(nil)).Elem()
+	if ttype.Kind() != reflect.Struct {
+		return nil, errors.New("section type must be a struct")
+	}
+
+	sec := &Section[T]{}
+	decoded, err := registerSection(name, ttype, sec.store)
+	if err != nil {
+		return nil, err
+	}
+	sec.store(decoded)
+	return sec, nil
+}
+
+// 解析并登记配置段
+// 持有配置加载锁, 登记前后不会遗漏重新加载
+func registerSection(name string, ttype reflect.Type, store func(val reflect.Value)) (reflect.Value, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	mu.RLock()
+	tree := configTree
+	mu.RUnlock()
+
+	decoded, err := decodeSection(tree, name, ttype)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	sectionMu.Lock()
+	defer sectionMu.Unlock()
+	sections = append(sections, &section{name: name, ttype: ttype, store: store})
+	return decoded, nil
+}
+
+// 解析配置段为新的结构体值
+func decodeSection(tree map[string]interface{}, name string, ttype reflect.Type) (reflect.Value, error) {
+	path := strings.Split(name, ".")
+	ptr := reflect.New(ttype)
+	if err := applyDefaults(ptr.Interface()); err != nil {
+		return reflect.Value{}, fmt.Errorf("section %s: %s", name, err.Error())
+	}
+
+	if node, ok := lookupTree(tree, path); ok && node != nil {
+		branch, isMap := node.(map[string]interface{})
+		if !isMap {
+			return reflect.Value{}, fmt.Errorf("section %s: not a mapping", name)
+		}
+		if err := decodeNode(branch, ptr.Interface()); err != nil {
+			return reflect.Value{}, fmt.Errorf("section %s: %s", name, err.Error())
+		}
+	}
+
+	if err := applyEnvOverrides(ptr.Interface(), path...); err != nil {
+		return reflect.Value{}, fmt.Errorf("section %s: %s", name, err.Error())
+	}
+	if err := applyArgOverrides(ptr.Interface(), path...); err != nil {
+		return reflect.Value{}, fmt.Errorf("section %s: %s", name, err.Error())
+	}
+
+	v := &validator{}
+	v.requiredFields(ptr.Interface(), path...)
+	if err := v.err(); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return ptr.Elem(), nil
+}
+
+// 使用新的配置树解析所有热更新的配置段
+// 返回的函数用于保存解析结果, 不修改调用方的结构体
+func decodeSections(tree map[string]interface{}) (func(), error) {
+	sectionMu.Lock()
+	var watched []*section
+	for _, sec := range sections {
+		if sec.store != nil {
+			watched = append(watched, sec)
+		}
+	}
+	sectionMu.Unlock()
+
+	values := make([]reflect.Value, len(watched))
+	for i, sec := range watched {
+		decoded, err := decodeSection(tree, sec.name, sec.ttype)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = decoded
+	}
+
+	return func() {
+		for i, sec := range watched {
+			sec.store(values[i])
+		}
+	}, nil
+}
+
+// 按路径查找配置树节点
+func lookupTree(tree map[string]interface{}, path []string) (interface{}, bool) {
+	var node interface{} = tree
+	for _, key := range path {
+		branch, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if node, ok = branch[key]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type paymentSection struct {
+	Gateway string        `yaml:"gateway"`
+	Timeout time.Duration `yaml:"timeout" default:"3s"`
+}
+
+// 写入application.yml
+func writeBaseConfig(t *testing.T, dir string, content string) {
+	if err := os.WriteFile(filepath.Join(dir, baseConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSectionReload(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(configDirEnv, dir)
+	writeBaseConfig(t, dir, "profile: test\npayment:\n  gateway: https://a.example.com\n")
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	bound := &paymentSection{}
+	if err := BindSection("payment", bound); err != nil {
+		t.Fatal(err)
+	}
+	watched, err := WatchSection[paymentSection]("payment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if watched.Get().Gateway != "https://a.example.com" || watched.Get().Timeout != 3*time.Second {
+		t.Fatalf("watched section = %+v", *watched.Get())
+	}
+
+	// 重新加载期间并发读取, 使用 -race 检查
+	stop := make(chan struct{})
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_ = bound.Gateway
+				_ = watched.Get().Gateway
+			}
+		}
+	}()
+
+	writeBaseConfig(t, dir, "profile: test\npayment:\n  gateway: https://b.example.com\n")
+	if _, err := Reload(); err != nil {
+		t.Fatal(err)
+	}
+	close(stop)
+	wg.Wait()
+
+	if watched.Get().Gateway != "https://b.example.com" {
+		t.Errorf("watched gateway = %s, want https://b.example.com", watched.Get().Gateway)
+	}
+	if bound.Gateway != "https://a.example.com" {
+		t.Errorf("bound gateway = %s, want unchanged https://a.example.com", bound.Gateway)
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 配置校验错误, 包含所有校验失败的配置项
+type ValidationError struct {
+	Problems []string
+}
+
+func (err *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(err.Problems, "\n  - ")
+}
+
+// 配置校验器, 收集所有问题后统一返回
+type validator struct {
+	problems []string
+}
+
+// 记录问题
+func (v *validator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// 检查必填项
+func (v *validator) required(key string, val string) {
+	if strings.TrimSpace(val) == "" {
+		v.addf("%s is required", key)
+	}
+}
+
+// 检查端口
+func (v *validator) port(key string, val string) {
+	if port, err := strconv.Atoi(val); err != nil || port <= 0 || port > 65535 {
+		v.addf("%s must be a valid port, got %q", key, val)
+	}
+}
+
+// 检查时间间隔
+func (v *validator) duration(key string, val string) {
+	if _, err := time.ParseDuration(val); err != nil {
+		v.addf("%s must be a valid duration such as 5s, got %q", key, val)
+	}
+}
+
+// 检查可选值
+func (v *validator) oneOf(key string, val string, options ...string) {
+	for _, option := range options {
+		if strings.EqualFold(val, option) {
+			return
+		}
+	}
+	v.addf("%s must be one of [%s], got %q", key, strings.Join(options, ", "), val)
+}
+
+// 检查带有 validate:"required" 标签的字段
+// prefix 配置路径前缀
+func (v *validator) requiredFields(target interface{}, prefix ...string) {
+	_ = walkFields(reflect.ValueOf(target), prefix, func(path []string, field reflect.Value, sf reflect.StructField) error {
+		if sf.Tag.Get("validate") == "required" && field.IsZero() {
+			v.addf("%s is required", strings.Join(path, "."))
+		}
+		return nil
+	})
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+// 校验程序配置
+func validate(app *applicationConfig) error {
+	v := &validator{}
+	v.requiredFields(app.commConfig)
+	v.requiredFields(app)
+
+	if app.Server.Port != "" {
+		v.port("server.port", app.Server.Port)
+	}
+
+	if app.Redis.Host != "" {
+		v.required("redis.port", app.Redis.Port)
+		if app.Redis.Timeout < 0 {
+			v.addf("redis.timeout must not be negative, got %d", app.Redis.Timeout)
+		}
+	}
+
+	if app.Rabbitmq.Address != "" && !strings.HasPrefix(app.Rabbitmq.Address, "amqp") {
+		v.addf("rabbitmq.address must start with amqp:// or amqps://")
+	}
+
+	// consul
+	if app.Consul.Host != "" || app.Consul.Port != "" {
+		v.required("consul.host", app.Consul.Host)
+		v.port("consul.port", app.Consul.Port)
+		v.required("consul.health-check", app.Consul.HealthCheck)
+		v.duration("consul.timeout", app.Consul.Timeout)
+		v.duration("consul.interval", app.Consul.Interval)
+		v.duration("consul.deregister-critical-service-after", app.Consul.DeregisterCriticalServiceAfter)
+	}
+	if app.Consul.Config.Enabled && (app.Consul.Host == "" || app.Consul.Port == "") {
+		v.addf("consul.config.enabled requires consul.host and consul.port")
+	}
+
+	// logger
+	v.oneOf("logger.default-logger", app.Logger.DefaultLogger, "file", "console", "mongo")
+	v.oneOf("logger.init-level", app.Logger.InitLevel, "debug", "info", "warn", "error", "fatal", "all", "off")
+	if strings.EqualFold(app.Logger.DefaultLogger, "mongo") && app.MongodbUri == "" {
+		v.addf("logger.default-logger mongo requires mongodb_uri")
+	}
+
+	return v.err()
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"looklapi/common/appcontext"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 配置文件检查间隔
+const watchInterval = 3 * time.Second
+
+var watchOnce = &sync.Once{}
+
+// 同步发布配置加载完成事件, 并开始监听配置文件及consul kv变更
+// 配置变更后重新加载配置, 并发布AppEventConfigChanged事件
+func Watch() {
+	watchOnce.Do(func() {
+		if err := appcontext.GetAppEventPublisher().PublishEventSync(appcontext.AppEventConfigInitialized(0)); err != nil {
+			fmt.Println(err)
+		}
+		go watch()
+		go remote.watch(context.Background())
+	})
+}
+
+func watch() {
+	stamps := fileStamps(watchedFiles())
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		latest := fileStamps(watchedFiles())
+		if reflect.DeepEqual(stamps, latest) {
+			continue
+		}
+
+		if _, err := Reload(); err != nil {
+			fmt.Println(err)
+		}
+		// 重新加载后profile可能变化, 重新获取监听文件
+		stamps = fileStamps(watchedFiles())
+	}
+}
+
+// 重新加载配置, 多个goroutine同时调用时依次执行
+// 返回变更的配置项, 存在变更时发布AppEventConfigChanged事件
+func Reload() ([]string, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	s, err := loadSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	storeSections, err := decodeSections(s.tree)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.RLock()
+	changed := diffTree(configTree, s.tree)
+	mu.RUnlock()
+
+	apply(s)
+	storeSections()
+
+	if len(changed) > 0 {
+		appcontext.GetAppEventPublisher().PublishEvent(appcontext.AppEventConfigChanged{Keys: changed})
+	}
+	return changed, nil
+}
+
+// 监听的配置文件
+func watchedFiles() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]string{}, configFiles...)
+}
+
+// 配置文件修改标记, 不存在的文件标记为空
+func fileStamps(files []string) map[string]string {
+	stamps := make(map[string]string, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			stamps[file] = fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+		} else {
+			stamps[file] = ""
+		}
+	}
+	return stamps
+}
+
+// 比较配置树, 返回变更的配置项
+func diffTree(old map[string]interface{}, latest map[string]interface{}) []string {
+	oldFlat, latestFlat := make(map[string]interface{}), make(map[string]interface{})
+	flattenTree(old, "", oldFlat)
+	flattenTree(latest, "", latestFlat)
+
+	var changed []string
+	for key, val := range latestFlat {
+		if oldVal, ok := oldFlat[key]; !ok || !reflect.DeepEqual(oldVal, val) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range oldFlat {
+		if _, ok := latestFlat[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
+}
+
+// 将配置树展开为 a.b.c 形式的叶子节点
+func flattenTree(tree map[string]interface{}, prefix string, result map[string]interface{}) {
+	for key, val := range tree {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		if child, ok := val.(map[string]interface{}); ok && len(child) > 0 {
+			flattenTree(child, path, result)
+		} else {
+			result[path] = val
+		}
+	}
+}
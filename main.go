@@ -2,15 +2,25 @@ package main
 
 import (
 	"looklapi/common/appcontext"
+	"looklapi/common/loggers"
 	_ "looklapi/common/service-discovery" // 导入以执行init，无需服务发现可移除
 	"looklapi/common/wireutils"
+	"looklapi/config"
 	_ "looklapi/mqconsumers"        // 导入以执行init，无需mq可移除
 	_ "looklapi/services/srv-proxy" // 导入以执行init
 	"looklapi/web/irisserver"
 )
 
 func main() {
-	wireutils.Inject()
-	appcontext.GetAppEventPublisher().PublishEvent(appcontext.AppEventBeanInjected(0))
+	config.MustLoad()
+	config.Watch()
+	wireutils.SetPropertySource(config.Properties())
+	if err := wireutils.Inject(); err != nil {
+		panic(err)
+	}
+	// 同步发布, 按订阅优先级完成服务注册、mq消费者绑定及定时任务启动后再启动web服务
+	if err := appcontext.GetAppEventPublisher().PublishEventSync(appcontext.AppEventBeanInjected(0)); err != nil {
+		loggers.GetLogger().Error(err)
+	}
 	irisserver.Start()
 }
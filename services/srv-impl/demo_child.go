@@ -4,7 +4,6 @@ import (
 	"looklapi/common/loggers"
 	"looklapi/common/wireutils"
 	srv_isrv "looklapi/services/srv-isrv"
-	"reflect"
 )
 
 type demoChild struct {
@@ -16,7 +15,7 @@ func init() {
 	srv.virtualFunc = srv.virtual
 
 	// 绑定接口映射
-	wireutils.Bind(reflect.TypeOf((*srv_isrv.InheritTestInterface)(nil)).Elem(), srv, false, 1)
+	wireutils.BindAs[srv_isrv.InheritTestInterface](srv, false, 1)
 }
 
 func (srv *demoChild) TestInherit(log string) {
@@ -4,7 +4,6 @@ import (
 	"looklapi/common/loggers"
 	"looklapi/common/wireutils"
 	"looklapi/services/srv-isrv"
-	"reflect"
 )
 
 type testSrvImpl struct {
@@ -13,7 +12,7 @@ type testSrvImpl struct {
 func init() {
 	testSrv := &testSrvImpl{}
 	// 绑定接口映射
-	wireutils.Bind(reflect.TypeOf((*srv_isrv.TestSrvInterface)(nil)).Elem(), testSrv, false, 1)
+	wireutils.BindAs[srv_isrv.TestSrvInterface](testSrv, false, 1)
 }
 
 func (srv *testSrvImpl) TestLog(log string) error {
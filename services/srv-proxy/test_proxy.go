@@ -5,7 +5,6 @@ import (
 	"looklapi/common/wireutils"
 	_ "looklapi/services/srv-impl" // 导入以执行init
 	"looklapi/services/srv-isrv"
-	"reflect"
 )
 
 // testsrv 代理
@@ -16,7 +15,7 @@ type testSrvProxy struct {
 func init() {
 	proxyIns := &testSrvProxy{}
 	// 绑定接口映射
-	wireutils.Bind(reflect.TypeOf((*srv_isrv.TestSrvInterface)(nil)).Elem(), proxyIns, true, 1)
+	wireutils.BindAs[srv_isrv.TestSrvInterface](proxyIns, true, 1)
 }
 
 // 代理实现
@@ -2,21 +2,18 @@ package irisserver_controller
 
 import (
 	"github.com/kataras/iris/v12"
-	"looklapi/common/appcontext"
 	serviceDiscovery "looklapi/common/service-discovery"
 	"looklapi/common/wireutils"
 	"net/http"
-	"reflect"
 )
 
 type serviceController struct {
-	app              *iris.Application
-	appInitCompleted bool
+	app *iris.Application
 }
 
 func init() {
 	serviceApi := &serviceController{}
-	wireutils.Bind(reflect.TypeOf((*ApiController)(nil)).Elem(), serviceApi, false, 1)
+	wireutils.BindAs[ApiController](serviceApi, false, 1)
 }
 
 func (ctr *serviceController) apiParty() string {
@@ -35,12 +32,6 @@ func (ctr *serviceController) RegisterRoute(irisApp *iris.Application) {
 // 服务健康检查
 func (ctr *serviceController) healthCheck(ctx iris.Context) {
 
-	if !ctr.appInitCompleted {
-		// 发布程序启动完成消息
-		ctr.appInitCompleted = true
-		appcontext.GetAppEventPublisher().PublishEvent(appcontext.AppEventInitCompleted(0))
-	}
-
 	if serviceDiscovery.GetServiceManager().IsHostCutoff() {
 		ctx.StatusCode(http.StatusForbidden)
 	} else {
@@ -9,7 +9,6 @@ import (
 	srv_isrv "looklapi/services/srv-isrv"
 	irisserver_middleware "looklapi/web/irisserver/irisserver-middleware"
 	"net/http"
-	"reflect"
 
 	"github.com/kataras/iris/v12"
 )
@@ -22,7 +21,7 @@ type testController struct {
 
 func init() {
 	testApi := &testController{}
-	wireutils.Bind(reflect.TypeOf((*ApiController)(nil)).Elem(), testApi, false, 1)
+	wireutils.BindAs[ApiController](testApi, false, 1)
 }
 
 func (ctr *testController) apiParty() string {
@@ -34,7 +33,7 @@ func (ctr *testController) RegisterRoute(irisApp *iris.Application) {
 	ctr.app = irisApp
 
 	//// 注入依赖
-	//ctr.testSrv = wireutils.Resovle(reflect.TypeOf((*srv_isrv.TestSrvInterface)(nil)).Elem()).(srv_isrv.TestSrvInterface)
+	//ctr.testSrv = wireutils.Resolve[srv_isrv.TestSrvInterface]()
 
 	// 绑定路由
 	irisserver_middleware.RegisterController(
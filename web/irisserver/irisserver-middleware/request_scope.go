@@ -0,0 +1,22 @@
+package irisserver_middleware
+
+import (
+	"github.com/kataras/iris/v12"
+	"looklapi/common/loggers"
+	"looklapi/common/wireutils"
+)
+
+// 请求作用域, 处理请求前创建, 请求结束后销毁请求作用域的实例
+func RequestScopeHandler() iris.Handler {
+	return func(context iris.Context) {
+		store := &memStoreWrapper{context.Values()}
+		wireutils.BeginRequestScope(store)
+		defer func() {
+			if err := wireutils.EndRequestScope(store); err != nil {
+				loggers.GetLogger().Error(err)
+			}
+		}()
+
+		context.Next()
+	}
+}
@@ -4,14 +4,22 @@ import (
 	"github.com/kataras/iris/v12"
 	"looklapi/common/wireutils"
 	irisserver_controller "looklapi/web/irisserver/irisserver-controller"
-	"reflect"
 )
 
+// 路由注册器
+type routeRegister struct {
+	controllers []irisserver_controller.ApiController `wired:"Autowired"` // 所有绑定的controller
+}
+
+var router = &routeRegister{}
+
+func init() {
+	wireutils.BindAs[*routeRegister](router, false, 1)
+}
+
 // 注册路由
 func registerRoute(irisApp *iris.Application) {
-	for _, ctr := range wireutils.ResovleAll(reflect.TypeOf((*irisserver_controller.ApiController)(nil)).Elem()) {
-		if api, ok := ctr.(irisserver_controller.ApiController); ok {
-			api.RegisterRoute(irisApp)
-		}
+	for _, ctr := range router.controllers {
+		ctr.RegisterRoute(irisApp)
 	}
 }
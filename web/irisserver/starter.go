@@ -3,8 +3,12 @@ package irisserver
 import (
 	"context"
 	"github.com/kataras/iris/v12"
+	irisHost "github.com/kataras/iris/v12/core/host"
 	"github.com/kataras/iris/v12/middleware/requestid"
+	"looklapi/common/appcontext"
+	"looklapi/common/loggers"
 	"looklapi/common/utils"
+	"looklapi/common/wireutils"
 	"looklapi/config"
 	"looklapi/web/irisserver/irisserver-middleware"
 	"sync"
@@ -16,6 +20,7 @@ func Start() {
 	app := iris.New()
 	app.UseRouter(requestid.New())
 	app.UseRouter(irisserver_middleware.PanicHandler())
+	app.UseRouter(irisserver_middleware.RequestScopeHandler())
 	app.UseRouter(irisserver_middleware.CorsHandler())
 	app.DoneGlobal(irisserver_middleware.ControllerRespWriter())
 	app.DoneGlobal(irisserver_middleware.ErrHandler())
@@ -27,10 +32,15 @@ func Start() {
 		wg.Add(1)
 		defer wg.Done()
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-		defer cancel()
-
-		app.Shutdown(ctx)
+		// 注销服务、停止mq消费者及定时任务后关闭web服务, 并按初始化的相反顺序销毁实例
+		if err := appcontext.Shutdown(time.Second*10, func(ctx context.Context) error {
+			if err := app.Shutdown(ctx); err != nil {
+				return err
+			}
+			return wireutils.Shutdown()
+		}); err != nil {
+			loggers.GetLogger().Error(err)
+		}
 	})
 
 	registerRoute(app)
@@ -49,6 +59,17 @@ func Start() {
 		RemoteAddrHeaders:                 []string{"X-Real-Ip", "X-Forwarded-For"},
 	})
 
-	host := utils.HostIp() + ":" + config.AppConfig.Server.Port
-	app.Run(iris.Addr(host), cfg)
+	host := utils.HostIp() + ":" + config.AppConfig().Server.Port
+	app.Run(iris.Addr(host, func(su *irisHost.Supervisor) {
+		// 开始监听端口后发布启动完成事件
+		su.RegisterOnServe(func(irisHost.TaskHost) {
+			publisher := appcontext.GetAppEventPublisher()
+			if err := publisher.PublishEventSync(appcontext.AppEventServerStarted{Addr: host}); err != nil {
+				loggers.GetLogger().Error(err)
+			}
+			if err := publisher.PublishEventSync(appcontext.AppEventInitCompleted(0)); err != nil {
+				loggers.GetLogger().Error(err)
+			}
+		})
+	}), cfg)
 }